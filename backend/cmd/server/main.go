@@ -1,7 +1,10 @@
 package main
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
+	"os"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
@@ -9,9 +12,15 @@ import (
 
 	"github.com/sakshamg567/doodlz/backend/internal/room"
 	"github.com/sakshamg567/doodlz/backend/logger"
+	"github.com/sakshamg567/doodlz/backend/pkg/utils"
 )
 
 func main() {
+	// extra word packs laid out as <dir>/<lang>/<category>.txt
+	if dir := os.Getenv("WORD_BANK_DIR"); dir != "" {
+		utils.UseWordBanks(utils.EmbeddedWordBank{}, utils.DirWordBank{Root: dir})
+	}
+
 	rm := room.NewRoomManager()
 	app := fiber.New()
 	app.Use(cors.New())
@@ -34,12 +43,24 @@ func main() {
 			return
 		}
 
+		if !r.CanJoin(playerID) {
+			c.WriteJSON(fiber.Map{
+				"type": room.TypeError,
+				"data": room.ErrorMsg{Code: room.ErrCodeRoomFull, Message: "room is full"},
+			})
+			room.CloseWith(c, room.CloseRoomFull, "room is full")
+			return
+		}
+
 		pl := room.NewPlayer(playerID, c)
+		// the room cleans the name up and makes it unique on register; a
+		// hello can still rename the player
+		pl.Name = c.Query("name")
 		r.Register <- pl
 
 		go pl.ReadPump(r)
 		pl.WritePump()
-	}))
+	}, websocket.Config{EnableCompression: true}))
 
 	app.Post("/room/create", rm.CreateRoomHandler)
 
@@ -57,6 +78,7 @@ func main() {
 			return c.Status(404).JSON(fiber.Map{"error": "room not found"})
 		}
 		r.Mu.RLock()
+		hostID := r.HostID
 		players := make(map[string]any, len(r.Players))
 		for k, v := range r.Players {
 			players[k] = struct {
@@ -68,11 +90,101 @@ func main() {
 		r.Mu.RUnlock()
 		return c.JSON(fiber.Map{
 			"roomId":  r.ID,
-			"hostId":  r.HostID,
+			"hostId":  hostID,
 			"players": players,
 		})
 	})
 
+	app.Get("/room/:id/canvas.png", func(c *fiber.Ctx) error {
+		r, ok := rm.GetRoom(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "room not found"})
+		}
+		img, err := r.CanvasPNG()
+		if err != nil {
+			logger.Error("canvas render for room %s failed: %v", r.ID, err)
+			return c.Status(500).JSON(fiber.Map{"error": "render failed"})
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		c.Type("png")
+		return c.Send(img)
+	})
+
+	app.Get("/room/:id/turns/:turn/thumbnail.png", func(c *fiber.Ctx) error {
+		r, ok := rm.GetRoom(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "room not found"})
+		}
+		turn, err := c.ParamsInt("turn")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid turn"})
+		}
+		img, ok, err := r.TurnThumbnail(turn)
+		switch {
+		case !ok:
+			return c.Status(404).JSON(fiber.Map{"error": "turn not found"})
+		case err != nil:
+			logger.Error("thumbnail render for room %s turn %d failed: %v", r.ID, turn, err)
+			return c.Status(500).JSON(fiber.Map{"error": "render failed"})
+		}
+		c.Type("png")
+		return c.Send(img)
+	})
+
+	app.Get("/room/:id/turns/:turn/replay.gif", func(c *fiber.Ctx) error {
+		r, ok := rm.GetRoom(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "room not found"})
+		}
+		turn, err := c.ParamsInt("turn")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid turn"})
+		}
+		img, ok, err := r.TurnReplayGIF(turn)
+		switch {
+		case !ok:
+			return c.Status(404).JSON(fiber.Map{"error": "turn not found"})
+		case err != nil:
+			logger.Error("replay render for room %s turn %d failed: %v", r.ID, turn, err)
+			return c.Status(500).JSON(fiber.Map{"error": "render failed"})
+		}
+		c.Attachment(fmt.Sprintf("doodlz-%s-turn-%d.gif", r.ID, turn))
+		return c.Send(img)
+	})
+
+	app.Get("/room/:id/turns/:turn/timeline.json", func(c *fiber.Ctx) error {
+		r, ok := rm.GetRoom(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "room not found"})
+		}
+		turn, err := c.ParamsInt("turn")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid turn"})
+		}
+		timeline, ok := r.TurnTimeline(turn)
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "turn not found"})
+		}
+		c.Attachment(fmt.Sprintf("doodlz-%s-turn-%d.json", r.ID, turn))
+		return c.JSON(timeline)
+	})
+
+	// reload the word bank without dropping rooms; only exposed with a token set
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		app.Post("/admin/words/reload", func(c *fiber.Ctx) error {
+			if subtle.ConstantTimeCompare([]byte(c.Get("X-Admin-Token")), []byte(token)) != 1 {
+				return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+			}
+			if err := utils.ReloadWords(); err != nil {
+				logger.Error("word bank reload failed: %v", err)
+				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			}
+			packs, _ := utils.PackNames()
+			logger.Info("word bank reloaded: %v", packs)
+			return c.JSON(fiber.Map{"packs": packs})
+		})
+	}
+
 	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
 
 	logger.EnableLogging(true)
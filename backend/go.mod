@@ -3,13 +3,14 @@ module github.com/sakshamg567/doodlz/backend
 go 1.24.1
 
 require (
+	github.com/agnivade/levenshtein v1.2.1
 	github.com/gofiber/contrib/websocket v1.3.4
 	github.com/gofiber/fiber/v2 v2.52.9
 	github.com/gorilla/websocket v1.5.3
+	golang.org/x/text v0.21.0
 )
 
 require (
-	github.com/agnivade/levenshtein v1.2.1 // indirect
 	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/fasthttp/websocket v1.5.8 // indirect
 	github.com/google/uuid v1.6.0 // indirect
@@ -0,0 +1,113 @@
+package room
+
+import (
+	"sync"
+
+	"github.com/sakshamg567/doodlz/backend/pkg/raster"
+)
+
+// server side renders use a 4:3 canvas
+const (
+	CanvasWidth  = 800
+	CanvasHeight = 600
+
+	thumbWidth  = 200
+	thumbHeight = 150
+)
+
+// turnRecord is what is kept of a finished turn.
+type turnRecord struct {
+	Round     int
+	DrawerID  string
+	Word      string
+	final     []raster.Stroke // the canvas as the turn ended
+	thumbnail []byte          // PNG, rendered on first download
+	timeline  []TimelineEvent
+	gif       []byte // rendered on first download
+}
+
+// canvasCache keeps the last canvas.png so downloads of an unchanged canvas
+// don't render it again.
+type canvasCache struct {
+	mu  sync.Mutex // held while rendering, so requests wait on one render
+	rev int64      // Room.canvasRev png was rendered at
+	png []byte
+}
+
+func rasterStrokes(strokes []Stroke) []raster.Stroke {
+	out := make([]raster.Stroke, len(strokes))
+	for i, s := range strokes {
+		pts := make([]raster.Point, len(s.Paths))
+		for j, p := range s.Paths {
+			pts[j] = raster.Point{X: p.X, Y: p.Y}
+		}
+		out[i] = raster.Stroke{Color: s.StrokeColor, Width: float64(s.StrokeWidth), Points: pts}
+	}
+	return out
+}
+
+// CanvasPNG renders what is on the canvas right now. The render is reused
+// until the canvas changes.
+func (r *Room) CanvasPNG() ([]byte, error) {
+	r.canvas.mu.Lock()
+	defer r.canvas.mu.Unlock()
+
+	r.Mu.RLock()
+	rev := r.canvasRev
+	if r.canvas.png != nil && r.canvas.rev == rev {
+		r.Mu.RUnlock()
+		return r.canvas.png, nil
+	}
+	strokes := rasterStrokes(r.Strokes)
+	r.Mu.RUnlock()
+
+	data, err := raster.PNG(strokes, CanvasWidth, CanvasHeight)
+	if err != nil {
+		return nil, err
+	}
+	r.canvas.rev, r.canvas.png = rev, data
+	return data, nil
+}
+
+// recordTurnLocked keeps the final canvas and the timeline of the turn that
+// is ending and returns its index, the one clients use to fetch them.
+// Nothing is rendered here; that waits for a download, outside r.Mu.
+func (r *Room) recordTurnLocked() int {
+	g := r.Game
+	r.turns = append(r.turns, &turnRecord{
+		Round:    g.Round,
+		DrawerID: g.DrawerID,
+		Word:     g.word,
+		final:    rasterStrokes(r.Strokes),
+		timeline: r.timeline,
+	})
+	r.timeline = nil
+	return len(r.turns) - 1
+}
+
+// TurnThumbnail returns the PNG thumbnail of a finished turn of the current
+// or last game.
+func (r *Room) TurnThumbnail(turn int) ([]byte, bool, error) {
+	r.Mu.RLock()
+	if turn < 0 || turn >= len(r.turns) {
+		r.Mu.RUnlock()
+		return nil, false, nil
+	}
+	t := r.turns[turn]
+	cached := t.thumbnail
+	r.Mu.RUnlock()
+
+	if cached != nil {
+		return cached, true, nil
+	}
+
+	data, err := raster.PNG(t.final, thumbWidth, thumbHeight)
+	if err != nil {
+		return nil, true, err
+	}
+
+	r.Mu.Lock()
+	t.thumbnail = data
+	r.Mu.Unlock()
+	return data, true, nil
+}
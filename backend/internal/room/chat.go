@@ -0,0 +1,64 @@
+package room
+
+import (
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// leaksWord reports whether msg gives away word or one of its aliases,
+// with the same plural tolerance guesses get; see leaksAnswer.
+func leaksWord(msg, word string, aliases []string, pluralTolerant bool) bool {
+	msgTokens := foldTokens(msg)
+	for _, answer := range append([]string{word}, aliases...) {
+		if leaksAnswer(msgTokens, answer, pluralTolerant) {
+			return true
+		}
+	}
+	return false
+}
+
+// leaksAnswer reports whether msgTokens contain answer as whole tokens,
+// spelled out with gaps or punctuation in between ("c a t", "c.a.t"), or a
+// near miss of it. Words merely hidden inside other words ("category") do
+// not count.
+func leaksAnswer(msgTokens []string, answer string, pluralTolerant bool) bool {
+	wordTokens := foldTokens(answer)
+	if len(wordTokens) == 0 {
+		return false
+	}
+	target := strings.Join(wordTokens, "")
+	maxDist := closeThreshold(answer)
+	slack := maxDist
+	if pluralTolerant {
+		slack += len("es")
+	}
+
+	for i := range msgTokens {
+		window := ""
+		for j := i; j < len(msgTokens) && len(window) < len(target)+slack; j++ {
+			window += msgTokens[j]
+			if window == target || (pluralTolerant && samePlural(window, target)) {
+				return true
+			}
+			// near misses only for runs of as many tokens as the word has
+			if j-i+1 == len(wordTokens) && levenshtein.ComputeDistance(window, target) <= maxDist {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guessedChannelLocked returns the players allowed to read the guessed-only
+// channel: the drawer and everyone who has found the word.
+func (r *Room) guessedChannelLocked() []*Player {
+	g := r.Game
+	out := make([]*Player, 0, len(g.GuessedPlayers)+1)
+	for id, pl := range r.Players {
+		if id == g.DrawerID || g.GuessedPlayers[id] {
+			out = append(out, pl)
+		}
+	}
+	return out
+}
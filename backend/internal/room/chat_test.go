@@ -0,0 +1,35 @@
+package room
+
+import "testing"
+
+func TestLeaksWord(t *testing.T) {
+	tests := []struct {
+		msg, word      string
+		aliases        []string
+		pluralTolerant bool
+		want           bool
+	}{
+		{"it's a cat", "cat", nil, false, true},
+		{"c a t", "cat", nil, false, true},
+		{"C.A.T!", "cat", nil, false, true},
+		{"ice cream", "ice cream", nil, false, true},
+		{"icecream", "ice cream", nil, false, true},
+		{"elephent", "elephant", nil, false, true},
+		{"i want it", "ant", nil, false, false},
+		{"open the door", "pen", nil, false, false},
+		{"category", "cat", nil, false, false},
+		{"nice one", "ice", nil, false, false},
+		{"kitty!", "cat", []string{"kitty"}, false, true},
+		{"k i t t y", "cat", []string{"kitty"}, false, true},
+		{"cats", "cat", nil, true, true},
+		{"c a t s", "cat", nil, true, true},
+		{"berries", "berry", nil, true, true},
+		{"cats", "cat", nil, false, false},
+		{"cattle", "cat", nil, true, false},
+	}
+	for _, tt := range tests {
+		if got := leaksWord(tt.msg, tt.word, tt.aliases, tt.pluralTolerant); got != tt.want {
+			t.Errorf("leaksWord(%q, %q, %q, %v) = %v, want %v", tt.msg, tt.word, tt.aliases, tt.pluralTolerant, got, tt.want)
+		}
+	}
+}
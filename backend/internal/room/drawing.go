@@ -0,0 +1,153 @@
+package room
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sakshamg567/doodlz/backend/logger"
+)
+
+func (r *Room) handleDrawPoint(p *Player, pt DrawPoint) {
+	pt, serr := sanitizeDrawPoint(pt)
+	if serr != nil {
+		r.rejectStroke(p, serr)
+		return
+	}
+	r.BroadcastPoint(p, TypeDrawPoint, pt)
+}
+
+// handleStroke commits a finished stroke. It is echoed to everyone,
+// the drawer included, so every client learns the ID it was given.
+func (r *Room) handleStroke(p *Player, in StrokeIn) {
+	stroke, serr := sanitizeStroke(in)
+	if serr != nil {
+		r.rejectStroke(p, serr)
+		return
+	}
+
+	r.Mu.Lock()
+	if serr = r.checkBudgetLocked(stroke); serr == nil {
+		r.addStrokeLocked(stroke, time.Now())
+	}
+	r.Mu.Unlock()
+
+	if serr != nil {
+		r.rejectStroke(p, serr)
+		return
+	}
+	r.flushWS()
+}
+
+func (r *Room) handleClear(p *Player, _ ClearIn) {
+	r.Mu.Lock()
+	r.resetCanvasLocked()
+	r.recordLocked(TimelineEvent{Kind: TimelineClear})
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+func (r *Room) handleUndo(p *Player, in UndoIn) {
+	r.Mu.Lock()
+	i := len(r.Strokes) - 1
+	if in.StrokeID != "" {
+		i = strokeIndex(r.Strokes, in.StrokeID)
+	}
+	if i < 0 {
+		r.sendError(p, ErrCodeUnknownStroke, "nothing to undo")
+		r.Mu.Unlock()
+		return
+	}
+
+	s := r.Strokes[i]
+	r.Strokes = append(r.Strokes[:i], r.Strokes[i+1:]...)
+	r.canvasRev++
+	r.redo = append(r.redo, s)
+	r.recordLocked(TimelineEvent{Kind: TimelineUndo, StrokeID: s.ID})
+	r.queueWS(TypeUndo, StrokeRemoved{StrokeID: s.ID})
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+func (r *Room) handleRedo(p *Player, in RedoIn) {
+	r.Mu.Lock()
+	i := len(r.redo) - 1
+	if in.StrokeID != "" {
+		i = strokeIndex(r.redo, in.StrokeID)
+	}
+	if i < 0 {
+		r.sendError(p, ErrCodeUnknownStroke, "nothing to redo")
+		r.Mu.Unlock()
+		return
+	}
+
+	s := r.redo[i]
+	if serr := r.checkBudgetLocked(s); serr != nil {
+		r.sendError(p, serr.Code, serr.Reason)
+		r.Mu.Unlock()
+		return
+	}
+	r.redo = append(r.redo[:i], r.redo[i+1:]...)
+
+	// back into its original place, so replays draw it in the same order
+	r.Strokes = insertBySeq(r.Strokes, s)
+	r.canvasRev++
+	r.recordLocked(TimelineEvent{Kind: TimelineRedo, StrokeID: s.ID})
+	r.queueWS(TypeRedo, s)
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+// nextStrokeIDLocked hands out the next stroke sequence number and its ID.
+func (r *Room) nextStrokeIDLocked() (int64, string) {
+	r.strokeSeq++
+	return r.strokeSeq, "s" + strconv.FormatInt(r.strokeSeq, 10)
+}
+
+// addStrokeLocked numbers s unless it already is, puts it on the canvas and
+// queues its broadcast. began is when its first point came in. A new stroke
+// ends the redo history like in any editor.
+func (r *Room) addStrokeLocked(s Stroke, began time.Time) {
+	if s.ID == "" {
+		s.Seq, s.ID = r.nextStrokeIDLocked()
+	}
+	r.Strokes = append(r.Strokes, s)
+	r.canvasRev++
+	r.recordLocked(TimelineEvent{
+		Kind:     TimelineStroke,
+		StrokeID: s.ID,
+		Stroke:   &s,
+		Duration: time.Since(began).Milliseconds(),
+	})
+	r.redo = nil
+	r.queueWS(TypeStroke, s)
+}
+
+// resetCanvasLocked wipes the canvas, the redo history and any stroke in
+// progress, and tells clients to wipe theirs.
+func (r *Room) resetCanvasLocked() {
+	r.Strokes = make([]Stroke, 0)
+	r.canvasRev++
+	r.redo = nil
+	r.endLiveLocked()
+	r.queueWS(TypeClear, struct{}{})
+}
+
+func strokeIndex(strokes []Stroke, id string) int {
+	for i := range strokes {
+		if strokes[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// rejectStroke tells the drawer why their drawing message was dropped.
+func (r *Room) rejectStroke(p *Player, serr *StrokeError) {
+	logger.Info("Player %s - rejected drawing: %v", p.ID, serr)
+	r.Mu.RLock()
+	r.sendError(p, serr.Code, serr.Reason)
+	r.Mu.RUnlock()
+}
@@ -1,3 +1,389 @@
 package room
 
-// implement gamestate functionalities
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sakshamg567/doodlz/backend/logger"
+	"github.com/sakshamg567/doodlz/backend/pkg/utils"
+)
+
+const (
+	minPlayers = 2
+
+	chooseDuration = 15 * time.Second
+	roundEndDelay  = 5 * time.Second
+)
+
+// why a turn ended, sent with turn_end
+const (
+	TurnEndTimeUp      = "time_up"
+	TurnEndAllGuessed  = "all_guessed"
+	TurnEndDrawerLeft  = "drawer_left"
+	TurnEndNoWords     = "no_words"
+	TurnEndGameStopped = "game_stopped"
+)
+
+// Game loop
+//
+//	lobby -> choosing_word -> drawing -> (next drawer | round_end) -> ... -> game_end -> lobby
+//
+// Every *Locked method expects r.Mu to be held by the caller. Messages they
+// produce are queued with queueWS and sent by flushWS once the lock is gone.
+
+func newGameState(maxRounds int) *GameState {
+	return &GameState{
+		Phase:          GamePhaseLobby,
+		MaxRounds:      maxRounds,
+		GuessedPlayers: make(map[string]bool),
+	}
+}
+
+// StartGame kicks off round one on the host's request. Anyone else, or a
+// request outside the lobby or without enough players, gets an error back.
+func (r *Room) StartGame(p *Player) {
+	r.Mu.Lock()
+	defer func() {
+		r.Mu.Unlock()
+		r.flushWS()
+	}()
+
+	switch {
+	case !r.isHost(p):
+		r.sendError(p, ErrCodeNotHost, "only the host can start the game")
+	case r.Game.Phase != GamePhaseLobby:
+		r.sendError(p, ErrCodeWrongPhase, "the game has already started")
+	case len(r.Players) < minPlayers:
+		r.sendError(p, ErrCodeNotEnoughPlayers, fmt.Sprintf("at least %d players are needed", minPlayers))
+	default:
+		r.startGameLocked()
+	}
+}
+
+func (r *Room) startGameLocked() {
+	g := r.Game
+	g.Round = 0
+	g.MaxRounds = r.Settings.Rounds
+	g.GuessedPlayers = make(map[string]bool)
+	r.turns = nil
+	for _, pl := range r.Players {
+		pl.Points = 0
+	}
+	for _, s := range r.Sessions {
+		s.Points = 0
+		s.Guessed = false
+	}
+
+	r.startRoundLocked()
+}
+
+func (r *Room) startRoundLocked() {
+	g := r.Game
+	g.Round++
+	g.drawQueue = r.playerOrderLocked()
+	r.nextTurnLocked()
+}
+
+// nextTurnLocked hands the pencil to the next queued player that is still in
+// the room or may rejoin it, or closes the round once everybody has drawn.
+func (r *Room) nextTurnLocked() {
+	g := r.Game
+	now := time.Now()
+	for len(g.drawQueue) > 0 {
+		id := g.drawQueue[0]
+		g.drawQueue = g.drawQueue[1:]
+
+		if r.isPresentLocked(id, now) {
+			r.beginChoosingLocked(id)
+			return
+		}
+	}
+	r.endRoundLocked()
+}
+
+func (r *Room) beginChoosingLocked(drawerID string) {
+	g := r.Game
+	g.DrawerID = drawerID
+	g.word = ""
+	g.WordMask = ""
+	g.WordLen = ""
+	g.StartedAtUnix = 0
+	g.EndsAtUnix = 0
+	g.GuessedPlayers = make(map[string]bool)
+	g.turnPoints = make(map[string]int)
+	for _, s := range r.Sessions {
+		s.Guessed = false
+	}
+	g.choices = r.pickChoicesLocked(r.Settings.WordChoices)
+	g.chooseDeadline = time.Now().Add(chooseDuration).Unix()
+	r.resetCanvasLocked()
+
+	r.setPhaseLocked(GamePhaseChoosingWord)
+	r.scheduleLocked(chooseDuration, r.autoChooseLocked)
+
+	if drawer, ok := r.Players[drawerID]; ok {
+		r.queueWSTo(drawer, TypeWordChoices, WordChoices{
+			Words:    g.choices,
+			Deadline: g.chooseDeadline,
+		})
+	}
+}
+
+// pickChoicesLocked draws up to n distinct candidate words.
+func (r *Room) pickChoicesLocked(n int) []string {
+	choices := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+
+	for tries := 0; len(choices) < n && tries < n*4; tries++ {
+		word, err := r.pickWordLocked()
+		if err != nil {
+			logger.Error("room %s: could not pick a word: %v", r.ID, err)
+			break
+		}
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		choices = append(choices, word)
+	}
+	return choices
+}
+
+// autoChooseLocked picks for a drawer who let the choose deadline pass. A
+// drawer who went offline and did not come back in time loses the turn.
+func (r *Room) autoChooseLocked() {
+	g := r.Game
+	if _, online := r.Players[g.DrawerID]; !online {
+		r.endTurnLocked(TurnEndDrawerLeft)
+		return
+	}
+	if len(g.choices) == 0 {
+		logger.Error("room %s: no words to choose from, skipping turn of %s", r.ID, g.DrawerID)
+		r.endTurnLocked(TurnEndNoWords)
+		return
+	}
+	r.beginDrawingLocked(g.choices[rand.Intn(len(g.choices))])
+}
+
+// handleChooseWord starts the drawing phase with the drawer's pick. Picks
+// from anyone else, outside the choosing phase or not among the choices get
+// an error back.
+func (r *Room) handleChooseWord(p *Player, in ChooseWordIn) {
+	r.Mu.Lock()
+	defer func() {
+		r.Mu.Unlock()
+		r.flushWS()
+	}()
+
+	g := r.Game
+	switch {
+	case g.Phase != GamePhaseChoosingWord:
+		r.sendError(p, ErrCodeWrongPhase, "no word is being chosen right now")
+	case g.DrawerID != p.ID:
+		r.sendError(p, ErrCodeWrongPhase, "only the drawer can choose the word")
+	case !slices.Contains(g.choices, in.Word):
+		r.sendError(p, ErrCodeBadPayload, fmt.Sprintf("%q is not one of the choices", in.Word))
+	default:
+		r.beginDrawingLocked(in.Word)
+	}
+}
+
+func (r *Room) beginDrawingLocked(word string) {
+	g := r.Game
+	now := time.Now()
+	drawDuration := time.Duration(r.Settings.DrawTime) * time.Second
+	g.word = word
+	g.aliases = utils.Aliases(word)
+	g.choices = nil
+	g.chooseDeadline = 0
+	g.revealed = make(map[int]bool)
+	g.WordMask = maskWord(word, g.revealed)
+	g.WordLen = wordLen(word)
+	g.StartedAtUnix = now.Unix()
+	g.EndsAtUnix = now.Add(drawDuration).Unix()
+	g.hintAt = hintSchedule(now, drawDuration, r.Settings.hintFractions())
+	r.drawStart = now
+	r.timeline = nil
+
+	r.setPhaseLocked(GamePhaseDrawing)
+	r.scheduleLocked(drawDuration, func() { r.endTurnLocked(TurnEndTimeUp) })
+
+	if drawer, ok := r.Players[g.DrawerID]; ok {
+		r.queueWSTo(drawer, TypeWordChosen, WordChosen{Word: word})
+	}
+}
+
+// endTurnLocked finishes the current drawer's turn and tells everyone how it
+// went. Calling it outside of a turn is a no-op so timers and disconnects can
+// race safely.
+func (r *Room) endTurnLocked(reason string) {
+	g := r.Game
+	if g.Phase != GamePhaseChoosingWord && g.Phase != GamePhaseDrawing {
+		return
+	}
+
+	r.closeTurnLocked(reason)
+
+	g.word = ""
+	g.aliases = nil
+	g.choices = nil
+	g.chooseDeadline = 0
+	g.hintAt = nil
+	r.nextTurnLocked()
+}
+
+// closeTurnLocked scores the drawer, keeps a record of the drawing and
+// queues turn_end.
+func (r *Room) closeTurnLocked(reason string) {
+	r.scoreDrawerLocked()
+	summary := r.turnSummaryLocked(reason)
+	summary.Turn = r.recordTurnLocked()
+	r.queueWS(TypeTurnEnd, summary)
+}
+
+func (r *Room) endRoundLocked() {
+	g := r.Game
+	g.DrawerID = ""
+	g.word = ""
+
+	if g.Round >= g.MaxRounds {
+		r.endGameLocked()
+		return
+	}
+
+	r.setPhaseLocked(GamePhaseRoundEnd)
+	r.scheduleLocked(roundEndDelay, r.startRoundLocked)
+}
+
+// stopGameLocked ends the game early, closing the turn that is in progress.
+func (r *Room) stopGameLocked() {
+	g := r.Game
+	if g.Phase == GamePhaseChoosingWord || g.Phase == GamePhaseDrawing {
+		r.closeTurnLocked(TurnEndGameStopped)
+	}
+	r.endGameLocked()
+}
+
+// endGameLocked shows the final standings. The last turn has already been
+// closed by whoever got here.
+func (r *Room) endGameLocked() {
+	g := r.Game
+	g.DrawerID = ""
+	g.word = ""
+	g.drawQueue = nil
+
+	r.setPhaseLocked(GamePhaseGameEnd)
+	r.queueWS(TypeGameEnd, r.gameSummaryLocked())
+	r.scheduleLocked(time.Duration(r.Settings.LobbyDelay)*time.Second, r.resetToLobbyLocked)
+}
+
+func (r *Room) resetToLobbyLocked() {
+	g := r.Game
+	g.Round = 0
+	g.DrawerID = ""
+	g.WordMask = ""
+	g.WordLen = ""
+	g.StartedAtUnix = 0
+	g.EndsAtUnix = 0
+	g.GuessedPlayers = make(map[string]bool)
+	r.resetCanvasLocked()
+
+	r.setPhaseLocked(GamePhaseLobby)
+}
+
+// handlePlayerLeftLocked keeps the loop moving when someone drops mid game.
+func (r *Room) handlePlayerLeftLocked(playerID string) {
+	g := r.Game
+	if g.Phase == GamePhaseLobby || g.Phase == GamePhaseGameEnd {
+		return
+	}
+
+	switch {
+	case playerID == g.DrawerID:
+		r.endTurnLocked(TurnEndDrawerLeft)
+	case r.allGuessedLocked():
+		r.endTurnLocked(TurnEndAllGuessed)
+	}
+}
+
+// allGuessedLocked reports whether there are guessers and every one of them
+// has found the word. Players inside their rejoin grace still count, so a
+// reload does not end the turn for them.
+func (r *Room) allGuessedLocked() bool {
+	g := r.Game
+	if g.Phase != GamePhaseDrawing {
+		return false
+	}
+	guessers := r.guessersLocked(time.Now())
+	for _, id := range guessers {
+		if !g.GuessedPlayers[id] {
+			return false
+		}
+	}
+	return len(guessers) > 0
+}
+
+// guessersLocked lists everyone besides the drawer who takes part in the
+// turn: players online or inside their rejoin grace, and those who guessed
+// before leaving.
+func (r *Room) guessersLocked(now time.Time) []string {
+	g := r.Game
+	seen := make(map[string]bool)
+	for id := range r.Players {
+		seen[id] = true
+	}
+	for id := range r.Sessions {
+		if r.isPresentLocked(id, now) {
+			seen[id] = true
+		}
+	}
+	for id, guessed := range g.GuessedPlayers {
+		if guessed {
+			seen[id] = true
+		}
+	}
+	delete(seen, g.DrawerID)
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (r *Room) setPhaseLocked(phase string) {
+	g := r.Game
+	g.Phase = phase
+	r.deadline = time.Time{}
+	r.onDeadline = nil
+
+	r.queueWS(TypePhaseChange, *g)
+}
+
+// scheduleLocked arms the room clock to run fn after d. Any phase change
+// disarms it, so a stale deadline can never fire into the wrong phase.
+func (r *Room) scheduleLocked(d time.Duration, fn func()) {
+	r.deadline = time.Now().Add(d)
+	r.onDeadline = fn
+}
+
+// playerOrderLocked returns the drawing order for a round.
+func (r *Room) playerOrderLocked() []string {
+	now := time.Now()
+	ids := make([]string, 0, len(r.Sessions))
+	for id := range r.Sessions {
+		if r.isPresentLocked(id, now) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range r.Players {
+		if _, ok := r.Sessions[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,116 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/sakshamg567/doodlz/backend/logger"
+)
+
+// ProtocolVersion is the version this server speaks. Clients older than
+// MinProtocolVersion are turned away; clients that never say hello are
+// taken as LegacyVersion and get the features of the original protocol.
+const (
+	ProtocolVersion    = 1
+	MinProtocolVersion = 1
+	LegacyVersion      = 0
+
+	MsgHello    = "hello"
+	TypeWelcome = "welcome"
+)
+
+// websocket close codes sent when a connection is refused
+const (
+	CloseBadHello           = 4000
+	CloseUnsupportedVersion = 4001
+	CloseRoomFull           = 4002
+)
+
+// optional features a client can ask for in its hello
+const (
+	CapStrokeStreaming = "stroke_streaming" // stroke_begin / stroke_points / stroke_cancel
+	CapCompression     = "compression"      // permessage-deflate on messages to the client
+)
+
+// serverCapabilities are the optional features this server can turn on for
+// a client that asks for them.
+var serverCapabilities = map[string]bool{
+	CapStrokeStreaming: true,
+	CapCompression:     true,
+}
+
+// Hello is the first message a client sends after connecting.
+type Hello struct {
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities"`
+	Name         string   `json:"name"`
+}
+
+// Welcome answers a Hello with what was agreed on.
+type Welcome struct {
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities"`
+	PlayerID     string   `json:"playerId"`
+}
+
+var errUnsupportedVersion = errors.New("unsupported protocol version")
+
+// negotiate checks the client's version and keeps the capabilities both
+// sides support, in the client's order.
+func negotiate(h Hello) ([]string, error) {
+	if h.Version < MinProtocolVersion || h.Version > ProtocolVersion {
+		return nil, fmt.Errorf("%w %d, server speaks %d to %d", errUnsupportedVersion, h.Version, MinProtocolVersion, ProtocolVersion)
+	}
+
+	caps := make([]string, 0, len(h.Capabilities))
+	seen := make(map[string]bool)
+	for _, c := range h.Capabilities {
+		if serverCapabilities[c] && !seen[c] {
+			seen[c] = true
+			caps = append(caps, c)
+		}
+	}
+	return caps, nil
+}
+
+// handleHello negotiates with a client whose first message is a hello. A
+// hello that cannot be read or names an unsupported version closes the
+// connection with a specific close code.
+func (r *Room) handleHello(p *Player, data json.RawMessage) {
+	var hello Hello
+	if err := json.Unmarshal(data, &hello); err != nil {
+		logger.Info("Player %s - invalid hello: %v", p.ID, err)
+		CloseWith(p.conn, CloseBadHello, "invalid hello")
+		return
+	}
+	caps, err := negotiate(hello)
+	if err != nil {
+		logger.Info("Player %s - %v", p.ID, err)
+		CloseWith(p.conn, CloseUnsupportedVersion, err.Error())
+		return
+	}
+
+	r.Mu.Lock()
+	p.setCapabilitiesLocked(caps)
+	r.sendWSMessageToPlayer(p, TypeWelcome, Welcome{
+		Version:      ProtocolVersion,
+		Capabilities: caps,
+		PlayerID:     p.ID,
+	})
+	if sanitizeName(hello.Name) != "" {
+		r.assignNameLocked(p, hello.Name)
+		r.queueWS(TypePlayerRenamed, PlayerRenamed{PlayerID: p.ID, Name: p.Name})
+	}
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+// CloseWith sends a close frame with code and reason, then drops the connection.
+func CloseWith(c *websocket.Conn, code int, reason string) {
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.Close()
+}
@@ -0,0 +1,91 @@
+package room
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// hintFractions returns the points of the draw time, as a fraction of it, at
+// which one more letter is revealed. Each hint halves the time that is left:
+// 1/2, 3/4, 7/8 ...
+func hintFractions(count int) []float64 {
+	fs := make([]float64, count)
+	left := 1.0
+	for i := range fs {
+		left /= 2
+		fs[i] = 1 - left
+	}
+	return fs
+}
+
+// hintSchedule turns fractions of the draw time into unix times for a turn
+// starting now.
+func hintSchedule(now time.Time, d time.Duration, fractions []float64) []int64 {
+	at := make([]int64, 0, len(fractions))
+	for _, f := range fractions {
+		at = append(at, now.Add(time.Duration(float64(d)*f)).Unix())
+	}
+	return at
+}
+
+// maskWord hides every letter and digit of word that is not in revealed.
+// Spaces, hyphens and other separators stay visible so the shape is clear.
+func maskWord(word string, revealed map[int]bool) string {
+	var b strings.Builder
+	for i, c := range []rune(word) {
+		if isHidden(c) && !revealed[i] {
+			b.WriteRune('_')
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func isHidden(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// wordLen describes the word shape, e.g. "ice cream" -> "3 5".
+func wordLen(word string) string {
+	parts := strings.Fields(word)
+	lens := make([]string, len(parts))
+	for i, part := range parts {
+		lens[i] = strconv.Itoa(len([]rune(part)))
+	}
+	return strings.Join(lens, " ")
+}
+
+// revealHintLocked uncovers one random letter once the next hint is due. It
+// always leaves at least one letter hidden.
+func (r *Room) revealHintLocked(now time.Time) {
+	g := r.Game
+	if len(g.hintAt) == 0 || now.Unix() < g.hintAt[0] {
+		return
+	}
+	g.hintAt = g.hintAt[1:]
+
+	hidden := make([]int, 0)
+	for i, c := range []rune(g.word) {
+		if isHidden(c) && !g.revealed[i] {
+			hidden = append(hidden, i)
+		}
+	}
+	if len(hidden) <= 1 {
+		return
+	}
+
+	g.revealed[hidden[rand.Intn(len(hidden))]] = true
+	g.WordMask = maskWord(g.word, g.revealed)
+
+	for id, pl := range r.Players {
+		mask := g.WordMask
+		if id == g.DrawerID || g.GuessedPlayers[id] {
+			mask = g.word
+		}
+		r.queueWSTo(pl, TypeHint, Hint{WordMask: mask})
+	}
+}
@@ -3,9 +3,9 @@ package room
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/sakshamg567/doodlz/backend/logger"
 	"github.com/sakshamg567/doodlz/backend/pkg/utils"
 )
 
@@ -24,34 +24,33 @@ func (rm *RoomManager) CreateRoomHandler(c *fiber.Ctx) error {
 	reqBody := c.Body()
 
 	var body struct {
-		HostId string `json:"hostId"`
+		HostId   string          `json:"hostId"`
+		Settings json.RawMessage `json:"settings"`
 	}
 
 	json.Unmarshal(reqBody, &body)
 
 	roomId := utils.GenShortID()
 
-	randomWord, err := utils.GetRandomWord(0)
-	if err != nil {
-		logger.Error(err.Error())
-		randomWord = ""
-	}
-
-	logger.Info("randomWord : \n\n\n", randomWord)
-
+	settings := defaultSettings()
 	room := &Room{
 		ID:         roomId,
 		Players:    make(map[string]*Player),
+		Sessions:   make(map[string]*PlayerSession),
 		HostID:     body.HostId,
 		Register:   make(chan *Player, 10), // ✅ Buffered
 		Unregister: make(chan *Player, 10), // ✅ Buffered
-		Broadcast:  make(chan []byte, 100),
+		Broadcast:  make(chan Outbound, 100),
 		done:       make(chan struct{}),
-		Game: &GameState{
-			Phase:    GamePhaseDrawing,
-			DrawerID: body.HostId,
-			word:     randomWord,
-		},
+		idle:       make(chan struct{}, 1),
+		createdAt:  time.Now(),
+		Game:       newGameState(settings.Rounds),
+		Settings:   settings,
+	}
+
+	rejected, err := room.applySettingsLocked(body.Settings)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	rm.Lock()
@@ -61,7 +60,8 @@ func (rm *RoomManager) CreateRoomHandler(c *fiber.Ctx) error {
 	go room.Run(rm)
 
 	return c.JSON(fiber.Map{
-		"roomId": roomId,
+		"roomId":        roomId,
+		"rejectedWords": rejected,
 	})
 
 }
@@ -73,8 +73,32 @@ func (rm *RoomManager) GetRoom(id string) (*Room, bool) {
 	return r, ok
 }
 
+// MarshalRooms lists the public rooms.
 func (rm *RoomManager) MarshalRooms() ([]byte, error) {
 	rm.RLock()
 	defer rm.RUnlock()
-	return json.Marshal(rm.Rooms)
+
+	type roomSummary struct {
+		RoomID     string `json:"roomId"`
+		Players    int    `json:"players"`
+		MaxPlayers int    `json:"maxPlayers"`
+		Phase      string `json:"phase"`
+		Preview    string `json:"preview"`
+	}
+
+	rooms := make([]roomSummary, 0, len(rm.Rooms))
+	for _, r := range rm.Rooms {
+		r.Mu.RLock()
+		if !r.Settings.Private {
+			rooms = append(rooms, roomSummary{
+				RoomID:     r.ID,
+				Players:    len(r.Players),
+				MaxPlayers: r.Settings.MaxPlayers,
+				Phase:      r.Game.Phase,
+				Preview:    "/room/" + r.ID + "/canvas.png",
+			})
+		}
+		r.Mu.RUnlock()
+	}
+	return json.Marshal(rooms)
 }
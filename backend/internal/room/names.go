@@ -0,0 +1,107 @@
+package room
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	maxNameLen  = 24 // runes
+	defaultName = "Player"
+)
+
+// sanitizeName trims s, drops control and invisible formatting characters
+// (zero-width spaces, joiners, bidi overrides), collapses runs of whitespace
+// and cuts the result to maxNameLen runes.
+func sanitizeName(s string) string {
+	var b strings.Builder
+	space := false
+	for _, c := range s {
+		switch {
+		case unicode.IsControl(c) && !unicode.IsSpace(c), unicode.Is(unicode.Cf, c):
+			continue
+		case unicode.IsSpace(c):
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteRune(' ')
+		}
+		space = false
+		b.WriteRune(c)
+	}
+
+	name := []rune(b.String())
+	if len(name) > maxNameLen {
+		name = name[:maxNameLen]
+	}
+	return strings.TrimSpace(string(name))
+}
+
+// uniqueNameLocked returns name, or name with a " 2", " 3", ... suffix when
+// another player in the room, or a session still able to rejoin, already
+// goes by it. Names compare case-insensitively.
+func (r *Room) uniqueNameLocked(playerID, name string) string {
+	if name == "" {
+		name = defaultName
+	}
+
+	taken := make(map[string]bool)
+	for id, pl := range r.Players {
+		if id != playerID {
+			taken[strings.ToLower(pl.Name)] = true
+		}
+	}
+	now := time.Now()
+	for id, s := range r.Sessions {
+		if _, online := r.Players[id]; online || id == playerID || s.Name == "" {
+			continue
+		}
+		// once the grace period is over the session can no longer take its
+		// name back, so it stops holding it
+		if now.Sub(s.offlineSince) <= rejoinGrace {
+			taken[strings.ToLower(s.Name)] = true
+		}
+	}
+
+	candidate := name
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		suffix := fmt.Sprintf(" %d", n)
+		base := []rune(name)
+		if len(base)+len(suffix) > maxNameLen {
+			base = base[:maxNameLen-len(suffix)]
+		}
+		candidate = strings.TrimSpace(string(base)) + suffix
+	}
+	return candidate
+}
+
+// assignNameLocked sanitizes name, makes it unique and stores it on p and
+// its session.
+func (r *Room) assignNameLocked(p *Player, name string) {
+	p.Name = r.uniqueNameLocked(p.ID, sanitizeName(name))
+	if s, ok := r.Sessions[p.ID]; ok {
+		s.Name = p.Name
+	}
+}
+
+// handleRename lets a player pick a new name while the room is in the lobby.
+func (r *Room) handleRename(p *Player, in RenameIn) {
+	r.Mu.Lock()
+	defer func() {
+		r.Mu.Unlock()
+		r.flushWS()
+	}()
+
+	switch {
+	case r.Game.Phase != GamePhaseLobby:
+		r.sendError(p, ErrCodeWrongPhase, "names can only be changed in the lobby")
+	case sanitizeName(in.Name) == "":
+		r.sendError(p, ErrCodeInvalidName, "name is empty")
+	default:
+		r.assignNameLocked(p, in.Name)
+		r.queueWS(TypePlayerRenamed, PlayerRenamed{PlayerID: p.ID, Name: p.Name})
+	}
+}
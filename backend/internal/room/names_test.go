@@ -0,0 +1,31 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUniqueNameLocked(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		session *PlayerSession
+		want    string
+	}{
+		{"free name", nil, "Alice"},
+		{"held by recent leaver", &PlayerSession{ID: "b", Name: "alice", offlineSince: now.Add(-rejoinGrace / 2)}, "Alice 2"},
+		{"released after grace", &PlayerSession{ID: "b", Name: "alice", offlineSince: now.Add(-2 * rejoinGrace)}, "Alice"},
+		{"own session", &PlayerSession{ID: "a", Name: "Alice", offlineSince: now}, "Alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Room{Players: map[string]*Player{}, Sessions: map[string]*PlayerSession{}}
+			if tt.session != nil {
+				r.Sessions[tt.session.ID] = tt.session
+			}
+			if got := r.uniqueNameLocked("a", "Alice"); got != tt.want {
+				t.Errorf("uniqueNameLocked = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package room
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/agnivade/levenshtein"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// foldings covers letters that have no decomposition, so stripping marks
+// leaves them alone.
+var foldings = map[rune]string{
+	'ø': "o", 'đ': "d", 'ð': "d", 'ħ': "h", 'ı': "i", 'ł': "l", 'ŀ': "l", 'ŧ': "t",
+	'æ': "ae", 'œ': "oe", 'þ': "th",
+}
+
+// stripMarks decomposes letters and drops the accents, "é" -> "e". A chain
+// keeps buffers of its own, so every call gets a fresh one; rooms fold
+// guesses in parallel.
+func stripMarks() transform.Transformer {
+	return transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+}
+
+// foldTokens runs the guess pipeline on s: fullwidth forms become ASCII,
+// case is folded, diacritics are stripped and everything that is not a letter
+// or digit separates tokens.
+func foldTokens(s string) []string {
+	s = width.Fold.String(s)
+	s = cases.Fold().String(s)
+	if stripped, _, err := transform.String(stripMarks(), s); err == nil {
+		s = stripped
+	}
+
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case foldings[c] != "":
+			b.WriteString(foldings[c])
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			b.WriteRune(c)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
+
+// normalizeGuess folds s and drops the separators, so "Ice-Cream",
+// "ice  cream" and "ICECREAM" all compare equal.
+func normalizeGuess(s string) string {
+	return strings.Join(foldTokens(s), "")
+}
+
+// singularForms returns s and what it could be with a plain English plural
+// ending taken off. "houses" gives "house" and "hous", "berries" "berry".
+func singularForms(s string) []string {
+	forms := []string{s}
+	if strings.HasSuffix(s, "ies") && len(s) > 4 {
+		forms = append(forms, s[:len(s)-3]+"y")
+	}
+	if strings.HasSuffix(s, "es") && len(s) > 4 {
+		forms = append(forms, s[:len(s)-2])
+	}
+	if strings.HasSuffix(s, "s") && len(s) > 3 {
+		forms = append(forms, s[:len(s)-1])
+	}
+	return forms
+}
+
+// samePlural reports whether a and b are the same word up to a plural
+// ending on either of them.
+func samePlural(a, b string) bool {
+	for _, fa := range singularForms(a) {
+		for _, fb := range singularForms(b) {
+			if fa == fb {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guessDistance is the edit distance between a guess and the closest of the
+// word and its aliases, after normalization. Zero means correct.
+func guessDistance(guess, word string, aliases []string, pluralTolerant bool) int {
+	g := normalizeGuess(guess)
+	best := -1
+	for _, answer := range append([]string{word}, aliases...) {
+		a := normalizeGuess(answer)
+		if a == "" {
+			continue
+		}
+
+		d := levenshtein.ComputeDistance(g, a)
+		if pluralTolerant && d > 0 && samePlural(g, a) {
+			d = 0
+		}
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+	if best < 0 {
+		return len(g) + 1
+	}
+	return best
+}
+
+// closeThreshold is how many edits still count as a close guess for word.
+func closeThreshold(word string) int {
+	switch n := len([]rune(normalizeGuess(word))); {
+	case n <= 3:
+		return 0
+	case n <= 5:
+		return 1
+	case n <= 9:
+		return 2
+	default:
+		return 3
+	}
+}
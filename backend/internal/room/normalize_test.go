@@ -0,0 +1,93 @@
+package room
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFoldTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Ice-Cream", []string{"ice", "cream"}},
+		{"  ice   cream ", []string{"ice", "cream"}},
+		{"ｃａｔ", []string{"cat"}},
+		{"Crème Brûlée", []string{"creme", "brulee"}},
+		{"ạǎ", []string{"aa"}},
+		{"éclair", []string{"eclair"}},
+		{"STRASSE", []string{"strasse"}},
+		{"Straße", []string{"strasse"}},
+		{"Ørsted", []string{"orsted"}},
+		{"Łódź", []string{"lodz"}},
+		{"ΣΊΣΥΦΟΣ", []string{"σισυφοσ"}},
+		{"!!!", []string{}},
+	}
+	for _, tt := range tests {
+		if got := foldTokens(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("foldTokens(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// Rooms normalize guesses on their own goroutines; run with -race.
+func TestNormalizeGuessParallel(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := normalizeGuess("Crème Brûlée éclair"); got != "cremebruleeeclair" {
+					t.Errorf("normalizeGuess = %q, want %q", got, "cremebruleeeclair")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGuessDistance(t *testing.T) {
+	tests := []struct {
+		guess, word    string
+		aliases        []string
+		pluralTolerant bool
+		want           int
+	}{
+		{"ICECREAM", "ice cream", nil, false, 0},
+		{"television", "tv", []string{"television"}, false, 0},
+		{"cats", "cat", nil, false, 1},
+		{"cats", "cat", nil, true, 0},
+		{"houses", "house", nil, true, 0},
+		{"horse", "horses", nil, true, 0},
+		{"berries", "berry", nil, true, 0},
+		{"buses", "bus", nil, true, 0},
+		{"glasses", "glass", nil, true, 0},
+		{"hous", "house", nil, true, 1},
+		{"elephent", "elephant", nil, false, 1},
+	}
+	for _, tt := range tests {
+		if got := guessDistance(tt.guess, tt.word, tt.aliases, tt.pluralTolerant); got != tt.want {
+			t.Errorf("guessDistance(%q, %q, %v, %v) = %d, want %d", tt.guess, tt.word, tt.aliases, tt.pluralTolerant, got, tt.want)
+		}
+	}
+}
+
+func TestCloseThreshold(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"cat", 0},
+		{"house", 1},
+		{"ice cream", 2},
+		{"refrigerator", 3},
+	}
+	for _, tt := range tests {
+		if got := closeThreshold(tt.word); got != tt.want {
+			t.Errorf("closeThreshold(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
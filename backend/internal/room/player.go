@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
@@ -19,6 +20,17 @@ type Player struct {
 	ctx    context.Context    `json:"-"`
 	cancel context.CancelFunc `json:"-"`
 	once   sync.Once          `json:"-"`
+
+	// drawing messages dropped because p was not the drawer, only touched by ReadPump
+	rejectedDraws int
+	// set by ReadPump once the first message, which may be a hello, is in
+	greeted bool
+
+	// agreed on in the hello handshake, guarded by the room's Mu; empty
+	// for legacy clients
+	capabilities map[string]bool
+	// whether WritePump compresses, see CapCompression
+	compress atomic.Bool
 }
 
 func NewPlayer(id string, c *websocket.Conn) *Player {
@@ -32,6 +44,21 @@ func NewPlayer(id string, c *websocket.Conn) *Player {
 	}
 }
 
+// setCapabilitiesLocked records the features negotiated for this connection.
+func (p *Player) setCapabilitiesLocked(caps []string) {
+	p.capabilities = make(map[string]bool, len(caps))
+	for _, c := range caps {
+		p.capabilities[c] = true
+	}
+	p.compress.Store(p.capabilities[CapCompression])
+}
+
+// hasLocked reports whether the client asked for capability c and the
+// server agreed. An empty c is always supported.
+func (p *Player) hasLocked(c string) bool {
+	return c == "" || p.capabilities[c]
+}
+
 func (p *Player) cleanup() {
 	p.once.Do(func() {
 		p.cancel() // Cancel context first
@@ -67,57 +94,18 @@ func (p *Player) ReadPump(r *Room) {
 				continue
 			}
 
-			// Add comprehensive loggerging
-
-			switch wsMsg.Type {
-			// case "start_game":
-			// logger.Info("Player %s - Processing start_game", p.ID)
-			// r.Mu.Lock()
-			// if r.Game == nil || r.Game.State == "waiting" {
-			// r.StartGame()
-			// }
-			// r.Mu.Unlock()
-
-			case "guess":
-				r.handleGuess(p, wsMsg)
-
-			case "draw_point":
-				r.BroadcastWSExcept(p, "draw_point", wsMsg.Data)
-
-			case "stroke":
-				var StrokeData Stroke
-
-				if err := json.Unmarshal(wsMsg.Data, &StrokeData); err != nil {
-					logger.Error("Player %s - Invalid stroke data: %v, data: %s", p.ID, err, string(wsMsg.Data))
+			// a client that opens with anything but a hello speaks the
+			// original protocol
+			if !p.greeted {
+				p.greeted = true
+				if wsMsg.Type == MsgHello {
+					r.handleHello(p, wsMsg.Data)
 					continue
 				}
-
-				r.Mu.Lock()
-				r.Strokes = append(r.Strokes, StrokeData)
-				r.Mu.Unlock()
-
-				r.BroadcastWSExcept(p, "stroke", StrokeData)
-
-			case "test":
-				logger.Info("Player %s - Processing test message", p.ID)
-				r.broadcast(msg)
-
-			case "clear":
-				r.Strokes = make([]Stroke, 0)
-				r.broadcast(msg)
-
-			case "undo":
-				r.Mu.Lock()
-				if len(r.Strokes) > 0 {
-					r.Strokes = r.Strokes[:len(r.Strokes)-1]
-				}
-				r.Mu.Unlock()
-
-				r.BroadcastWS("undo", `{}`)
-			default:
-				r.broadcast(msg)
+				logger.Info("Player %s - no hello, treating as protocol version %d", p.ID, LegacyVersion)
 			}
 
+			r.dispatch(p, wsMsg)
 		}
 	}
 }
@@ -143,6 +131,7 @@ func (p *Player) WritePump() {
 
 			// logger.Info("Player %s - Sending message: %s", p.ID, string(msg))
 
+			p.conn.EnableWriteCompression(p.compress.Load())
 			if err := p.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
 				logger.Error("WriteMessage error for player %s: %v", p.ID, err)
 				return
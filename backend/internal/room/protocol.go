@@ -0,0 +1,132 @@
+package room
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sakshamg567/doodlz/backend/logger"
+)
+
+// inbound message types
+const (
+	MsgStartGame      = "start_game"
+	MsgUpdateSettings = "update_settings"
+	MsgChooseWord     = "choose_word"
+	MsgGuess          = "guess"
+	MsgDrawPoint      = "draw_point"
+	MsgStroke         = "stroke"
+	MsgClear          = "clear"
+	MsgUndo           = "undo"
+	MsgRedo           = "redo"
+	MsgStrokeBegin    = "stroke_begin"
+	MsgStrokePoints   = "stroke_points"
+	MsgStrokeEnd      = "stroke_end"
+	MsgRename         = "rename"
+)
+
+type StartGameIn struct{}
+
+type ChooseWordIn struct {
+	Word string `json:"word"`
+}
+
+// GuessIn is everything typed into the chat box; Message is the older field name.
+type GuessIn struct {
+	Guess   string `json:"guess"`
+	Message string `json:"message"`
+}
+
+type RenameIn struct {
+	Name string `json:"name"`
+}
+
+// StrokeIn is a finished stroke. Its width is read as a plain number so an
+// out of range one gets invalid_stroke rather than failing to decode.
+type StrokeIn struct {
+	StrokeColor string  `json:"strokeColor"`
+	StrokeWidth float64 `json:"strokeWidth"`
+	Paths       []Point `json:"paths"`
+}
+
+type ClearIn struct{}
+
+// UndoIn removes StrokeID, or the newest stroke when it is empty.
+type UndoIn struct {
+	StrokeID string `json:"strokeId"`
+}
+
+// RedoIn puts StrokeID back, or the last undone stroke when it is empty.
+type RedoIn struct {
+	StrokeID string `json:"strokeId"`
+}
+
+// handler decodes the payload of one message type and runs it.
+type handler struct {
+	drawerOnly bool
+	handle     func(r *Room, p *Player, data json.RawMessage) error
+}
+
+// on builds a handler that decodes the payload into T before calling fn.
+func on[T any](fn func(r *Room, p *Player, in T)) handler {
+	return handler{handle: func(r *Room, p *Player, data json.RawMessage) error {
+		var in T
+		if len(data) > 0 && string(data) != "null" {
+			if err := json.Unmarshal(data, &in); err != nil {
+				return err
+			}
+		}
+		fn(r, p, in)
+		return nil
+	}}
+}
+
+// drawerOn is on for messages only the current drawer may send.
+func drawerOn[T any](fn func(r *Room, p *Player, in T)) handler {
+	h := on(fn)
+	h.drawerOnly = true
+	return h
+}
+
+var handlers = map[string]handler{
+	MsgStartGame:      on(func(r *Room, p *Player, _ StartGameIn) { r.StartGame(p) }),
+	MsgUpdateSettings: on((*Room).handleUpdateSettings),
+	MsgChooseWord:     on((*Room).handleChooseWord),
+	MsgGuess:          on((*Room).handleGuess),
+	MsgRename:         on((*Room).handleRename),
+	MsgDrawPoint:      drawerOn((*Room).handleDrawPoint),
+	MsgStroke:         drawerOn((*Room).handleStroke),
+	MsgClear:          drawerOn((*Room).handleClear),
+	MsgUndo:           drawerOn((*Room).handleUndo),
+	MsgRedo:           drawerOn((*Room).handleRedo),
+	MsgStrokeBegin:    drawerOn((*Room).handleStrokeBegin),
+	MsgStrokePoints:   drawerOn((*Room).handleStrokePoints),
+	MsgStrokeEnd:      drawerOn((*Room).handleStrokeEnd),
+}
+
+// abuse is logged every this many rejected drawing messages
+const rejectedDrawsLogEvery = 50
+
+// dispatch routes a message from p to its handler. Unknown types and bad
+// payloads are answered with an error, drawing messages from anyone but the
+// drawer are dropped and counted.
+func (r *Room) dispatch(p *Player, msg WSMessage) {
+	h, ok := handlers[msg.Type]
+	if !ok {
+		logger.Info("Player %s - unknown message type %q", p.ID, msg.Type)
+		r.sendError(p, ErrCodeUnknownType, fmt.Sprintf("unknown message type %q", msg.Type))
+		return
+	}
+
+	if h.drawerOnly && !r.isDrawer(p) {
+		p.rejectedDraws++
+		if p.rejectedDraws%rejectedDrawsLogEvery == 1 {
+			logger.Info("Player %s - dropped %s from non-drawer (%d rejected so far)", p.ID, msg.Type, p.rejectedDraws)
+		}
+		return
+	}
+
+	if err := h.handle(r, p, msg.Data); err != nil {
+		logger.Error("Player %s - invalid %s payload: %v, data: %s", p.ID, msg.Type, err, string(msg.Data))
+		r.sendError(p, ErrCodeBadPayload, fmt.Sprintf("invalid %s payload", msg.Type))
+	}
+}
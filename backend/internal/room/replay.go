@@ -0,0 +1,226 @@
+package room
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sakshamg567/doodlz/backend/pkg/raster"
+)
+
+// what a timeline event did to the canvas
+const (
+	TimelineStroke = "stroke"
+	TimelineUndo   = "undo"
+	TimelineRedo   = "redo"
+	TimelineClear  = "clear"
+)
+
+const (
+	replayWidth  = 320
+	replayHeight = 240
+
+	maxReplayFrames = 120
+	strokeSteps     = 4 // frames a stroke is drawn over in a replay
+	maxFrameDelay   = time.Second
+	lastFrameDelay  = 3 * time.Second
+)
+
+// TimelineEvent is one change to the canvas during a turn. At is the time
+// in milliseconds since drawing started; a stroke's Duration is how long it
+// took from the first point to the last.
+type TimelineEvent struct {
+	At       int64   `json:"at"`
+	Kind     string  `json:"kind"`
+	StrokeID string  `json:"strokeId,omitempty"`
+	Stroke   *Stroke `json:"stroke,omitempty"`
+	Duration int64   `json:"duration,omitempty"`
+}
+
+// TurnTimeline is the ordered drawing history of one finished turn.
+type TurnTimeline struct {
+	Turn     int             `json:"turn"`
+	Round    int             `json:"round"`
+	DrawerID string          `json:"drawerId"`
+	Word     string          `json:"word"`
+	Events   []TimelineEvent `json:"events"`
+}
+
+// TurnReplay points at the downloads for one turn, sent with game_end.
+type TurnReplay struct {
+	Turn      int    `json:"turn"`
+	Round     int    `json:"round"`
+	DrawerID  string `json:"drawerId"`
+	Word      string `json:"word"`
+	Thumbnail string `json:"thumbnail"`
+	GIF       string `json:"gif"`
+	Timeline  string `json:"timeline"`
+}
+
+// recordLocked adds ev to the running turn's timeline. Nothing is kept
+// outside the drawing phase.
+func (r *Room) recordLocked(ev TimelineEvent) {
+	if r.Game.Phase != GamePhaseDrawing {
+		return
+	}
+	ev.At = time.Since(r.drawStart).Milliseconds()
+	r.timeline = append(r.timeline, ev)
+}
+
+// replaysLocked lists the downloads of every finished turn.
+func (r *Room) replaysLocked() []TurnReplay {
+	out := make([]TurnReplay, len(r.turns))
+	for i, t := range r.turns {
+		base := fmt.Sprintf("/room/%s/turns/%d/", r.ID, i)
+		out[i] = TurnReplay{
+			Turn:      i,
+			Round:     t.Round,
+			DrawerID:  t.DrawerID,
+			Word:      t.Word,
+			Thumbnail: base + "thumbnail.png",
+			GIF:       base + "replay.gif",
+			Timeline:  base + "timeline.json",
+		}
+	}
+	return out
+}
+
+// TurnTimeline returns the recorded history of a finished turn.
+func (r *Room) TurnTimeline(turn int) (TurnTimeline, bool) {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+
+	if turn < 0 || turn >= len(r.turns) {
+		return TurnTimeline{}, false
+	}
+	t := r.turns[turn]
+	return TurnTimeline{
+		Turn:     turn,
+		Round:    t.Round,
+		DrawerID: t.DrawerID,
+		Word:     t.Word,
+		Events:   t.timeline,
+	}, true
+}
+
+// TurnReplayGIF renders the animated replay of a finished turn. The result
+// is cached on the turn.
+func (r *Room) TurnReplayGIF(turn int) ([]byte, bool, error) {
+	r.Mu.RLock()
+	if turn < 0 || turn >= len(r.turns) {
+		r.Mu.RUnlock()
+		return nil, false, nil
+	}
+	t := r.turns[turn]
+	cached := t.gif
+	r.Mu.RUnlock()
+
+	if cached != nil {
+		return cached, true, nil
+	}
+
+	data, err := raster.GIF(replayFrames(t.timeline), replayWidth, replayHeight)
+	if err != nil {
+		return nil, true, err
+	}
+
+	r.Mu.Lock()
+	t.gif = data
+	r.Mu.Unlock()
+	return data, true, nil
+}
+
+// replayFrames turns a timeline into animation frames. Strokes are drawn
+// over a few frames each unless that would make too many, in which case
+// frames are dropped evenly; the finished drawing is always the last frame.
+func replayFrames(events []TimelineEvent) []raster.Frame {
+	type shot struct {
+		at      int64
+		strokes []raster.Stroke
+	}
+
+	build := func(steps int) []shot {
+		var (
+			canvas  []Stroke
+			undone  = make(map[string]Stroke)
+			shots   = []shot{{at: 0}}
+			rasters = func(partial *Stroke) []raster.Stroke {
+				if partial == nil {
+					return rasterStrokes(canvas)
+				}
+				return rasterStrokes(append(append([]Stroke{}, canvas...), *partial))
+			}
+		)
+
+		for _, ev := range events {
+			switch ev.Kind {
+			case TimelineStroke:
+				if ev.Stroke == nil {
+					continue
+				}
+				s := *ev.Stroke
+				start := ev.At - ev.Duration
+				for k := 1; k < steps && len(s.Paths) > 1; k++ {
+					part := s
+					part.Paths = s.Paths[:(len(s.Paths)*k+steps-1)/steps]
+					shots = append(shots, shot{at: start + ev.Duration*int64(k)/int64(steps), strokes: rasters(&part)})
+				}
+				canvas = insertBySeq(canvas, s)
+			case TimelineUndo:
+				if i := strokeIndex(canvas, ev.StrokeID); i >= 0 {
+					undone[ev.StrokeID] = canvas[i]
+					canvas = append(canvas[:i:i], canvas[i+1:]...)
+				}
+			case TimelineRedo:
+				if s, ok := undone[ev.StrokeID]; ok {
+					delete(undone, ev.StrokeID)
+					canvas = insertBySeq(canvas, s)
+				}
+			case TimelineClear:
+				canvas = nil
+			default:
+				continue
+			}
+			shots = append(shots, shot{at: ev.At, strokes: rasters(nil)})
+		}
+		return shots
+	}
+
+	shots := build(strokeSteps)
+	if len(shots) > maxReplayFrames {
+		shots = build(1)
+	}
+	if n := len(shots); n > maxReplayFrames {
+		thinned := make([]shot, 0, maxReplayFrames)
+		for i := 0; i < maxReplayFrames-1; i++ {
+			thinned = append(thinned, shots[i*(n-1)/(maxReplayFrames-1)])
+		}
+		shots = append(thinned, shots[n-1])
+	}
+
+	frames := make([]raster.Frame, len(shots))
+	for i, s := range shots {
+		delay := lastFrameDelay
+		if i+1 < len(shots) {
+			delay = min(time.Duration(shots[i+1].at-s.at)*time.Millisecond, maxFrameDelay)
+		}
+		frames[i] = raster.Frame{Strokes: s.strokes, Delay: delay}
+	}
+	return frames
+}
+
+// insertBySeq puts s back into strokes in drawing order.
+func insertBySeq(strokes []Stroke, s Stroke) []Stroke {
+	out := make([]Stroke, 0, len(strokes)+1)
+	done := false
+	for _, c := range strokes {
+		if !done && c.Seq > s.Seq {
+			out = append(out, s)
+			done = true
+		}
+		out = append(out, c)
+	}
+	if !done {
+		out = append(out, s)
+	}
+	return out
+}
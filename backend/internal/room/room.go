@@ -6,7 +6,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/agnivade/levenshtein"
 	"github.com/sakshamg567/doodlz/backend/logger"
 )
 
@@ -17,18 +16,43 @@ type Room struct {
 	HostID     string
 	Register   chan *Player
 	Unregister chan *Player
-	Broadcast  chan []byte
+	Broadcast  chan Outbound
 	done       chan struct{}
 	Mu         sync.RWMutex
 	Game       *GameState
 	Strokes    []Stroke
+	Settings   RoomSettings
+
+	CustomWords []string
 
 	//internal
 	timerTicker *time.Ticker
 	stopTimer   chan struct{}
+	deadline    time.Time // when onDeadline fires, zero when disarmed
+	onDeadline  func()
+	pending     []Outbound // queued under Mu, sent by flushWS
+	strokeSeq   int64      // last sequence number handed to a stroke
+	canvasRev   int64      // bumped whenever Strokes changes
+	canvas      canvasCache
+	redo        []Stroke        // strokes undone this turn, most recent last
+	live        *liveStroke     // stroke being drawn, not yet in Strokes
+	turns       []*turnRecord   // finished turns of the current or last game
+	timeline    []TimelineEvent // canvas history of the running turn
+	drawStart   time.Time       // when the running turn's drawing phase began
+	idle        chan struct{}   // signalled by the clock once the room is abandoned
+	createdAt   time.Time
+}
+
+// Outbound is a broadcast waiting to go out. A non-empty Capability limits
+// it to players that negotiated that capability, a non-empty PlayerID to
+// that one player.
+type Outbound struct {
+	Payload    []byte
+	Capability string
+	PlayerID   string
 }
 
-func (r *Room) broadcast(msg []byte) {
+func (r *Room) broadcast(msg Outbound) {
 	r.Broadcast <- msg
 }
 
@@ -55,28 +79,89 @@ func (r *Room) BroadcastWS(t string, d any) {
 		}
 
 		if payload, err := json.Marshal(msg); err == nil {
-			r.broadcast(payload)
+			r.broadcast(Outbound{Payload: payload})
 		}
 	}
 }
 
-func (r *Room) handleGuess(p *Player, wsMsg WSMessage) {
-	start := time.Now()
+// queueWS records a broadcast while r.Mu is held; flushWS sends it once the
+// lock has been released so the Run loop is never blocked on us.
+func (r *Room) queueWS(t string, d any) {
+	r.queueOutbound(Outbound{}, t, d)
+}
+
+// queueWSFor is queueWS for messages only clients with capability c understand.
+func (r *Room) queueWSFor(c string, t string, d any) {
+	r.queueOutbound(Outbound{Capability: c}, t, d)
+}
+
+// queueWSTo is queueWS for a message meant for p alone. Going through the
+// queue keeps it in order with the broadcasts queued around it.
+func (r *Room) queueWSTo(p *Player, t string, d any) {
+	r.queueOutbound(Outbound{PlayerID: p.ID}, t, d)
+}
 
-	var payload struct {
-		Guess   string `json:"guess"`
-		Message string `json:"message"`
+func (r *Room) queueOutbound(out Outbound, t string, d any) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		logger.Error("queueWS: marshal %s failed: %v", t, err)
+		return
 	}
-	if err := json.Unmarshal(wsMsg.Data, &payload); err != nil {
-		logger.Info("handleGuess: player=%s invalid payload err=%v", p.ID, err)
+	payload, err := json.Marshal(WSMessage{Type: t, Data: data})
+	if err != nil {
+		logger.Error("queueWS: marshal %s failed: %v", t, err)
 		return
 	}
+	out.Payload = payload
+	r.pending = append(r.pending, out)
+}
+
+func (r *Room) flushWS() {
+	for _, msg := range r.takePendingWS() {
+		r.broadcast(msg)
+	}
+}
+
+// flushWSDirect is flushWS for the Run loop. Run is the only reader of
+// r.Broadcast, so it must never send on it and delivers to players itself.
+func (r *Room) flushWSDirect() {
+	for _, msg := range r.takePendingWS() {
+		r.deliver(msg)
+	}
+}
+
+func (r *Room) takePendingWS() []Outbound {
+	r.Mu.Lock()
+	msgs := r.pending
+	r.pending = nil
+	r.Mu.Unlock()
+	return msgs
+}
+
+// deliver hands msg to every player that can take it, waiting on slow ones
+// unless they go away.
+func (r *Room) deliver(msg Outbound) {
+	r.Mu.RLock()
+	for _, p := range r.Players {
+		if !p.hasLocked(msg.Capability) || (msg.PlayerID != "" && msg.PlayerID != p.ID) {
+			continue
+		}
+		select {
+		case p.send <- msg.Payload:
+		case <-p.ctx.Done():
+		}
+	}
+	r.Mu.RUnlock()
+}
+
+func (r *Room) handleGuess(p *Player, in GuessIn) {
+	start := time.Now()
 
 	raw := strings.TrimSpace(func() string {
-		if payload.Guess != "" {
-			return payload.Guess
+		if in.Guess != "" {
+			return in.Guess
 		}
-		return payload.Message
+		return in.Message
 	}())
 	if raw == "" {
 		logger.Info("handleGuess: player=%s empty input", p.ID)
@@ -86,7 +171,6 @@ func (r *Room) handleGuess(p *Player, wsMsg WSMessage) {
 	guessLower := strings.ToLower(raw)
 
 	playerID := p.ID
-	playerName := p.Name
 
 	// Decision flags collected under lock
 	var (
@@ -95,41 +179,48 @@ func (r *Room) handleGuess(p *Player, wsMsg WSMessage) {
 		closeDistance  int
 		sendCloseHint  bool
 		alreadyGuessed bool
+		leaked         bool
+		guessedOnly    []*Player
 	)
 
 	logger.Info("handleGuess: player=%s acquiring lock", p.ID)
 	r.Mu.Lock()
+	playerName := p.Name
 
 	game := r.Game
-	if game != nil &&
-		game.Phase == GamePhaseDrawing &&
-		game.word != "" &&
-		playerID != game.DrawerID {
+	inTurn := game != nil && game.Phase == GamePhaseDrawing && game.word != ""
 
+	switch {
+	case inTurn && (playerID == game.DrawerID || game.GuessedPlayers[playerID]):
+		// they know the word, keep it from everyone else
+		leaked = leaksWord(raw, game.word, game.aliases, r.Settings.PluralTolerant)
+		if playerID != game.DrawerID {
+			alreadyGuessed = true
+			guessedOnly = r.guessedChannelLocked()
+		}
+
+	case inTurn:
 		isGuessContext = true
 
 		if game.GuessedPlayers == nil {
 			game.GuessedPlayers = make(map[string]bool)
 		}
 
-		if game.GuessedPlayers[playerID] {
-			alreadyGuessed = true
-		} else {
-			target := strings.ToLower(game.word)
-			dist := levenshtein.ComputeDistance(guessLower, target)
-
-			if dist == 0 {
-				game.GuessedPlayers[playerID] = true
-				timeLeft := game.EndsAtUnix - time.Now().Unix()
-				if timeLeft < 0 {
-					timeLeft = 0
-				}
-				p.Points += 100 + int(timeLeft)
-				correct = true
-			} else if dist <= 2 {
-				closeDistance = dist
-				sendCloseHint = true
+		dist := guessDistance(raw, game.word, game.aliases, r.Settings.PluralTolerant)
+
+		if dist == 0 {
+			r.scoreGuessLocked(p)
+			game.GuessedPlayers[playerID] = true
+			correct = true
+
+			r.queueWSTo(p, TypeHint, Hint{WordMask: game.word})
+
+			if r.allGuessedLocked() {
+				r.endTurnLocked(TurnEndAllGuessed)
 			}
+		} else if dist <= closeThreshold(game.word) {
+			closeDistance = dist
+			sendCloseHint = true
 		}
 	}
 
@@ -138,59 +229,33 @@ func (r *Room) handleGuess(p *Player, wsMsg WSMessage) {
 		p.ID, isGuessContext, correct, sendCloseHint, alreadyGuessed, time.Since(start))
 
 	// Post-lock actions
+	if leaked {
+		logger.Info("handleGuess: player=%s blocked message revealing the word", p.ID)
+		r.sendError(p, ErrCodeWordLeak, "you can't give the word away in chat")
+		return
+	}
+
 	if alreadyGuessed {
-		// Echo original text only to that player (optional)
-		r.WsMsgTo(p, "message", struct {
-			Type string `json:"type"`
-			Data struct {
-				Sender struct {
-					ID   string `json:"ID"`
-					Name string `json:"Name"`
-				} `json:"sender"`
-				Message string `json:"message"`
-			} `json:"data"`
-		}{
-			Type: "chat_msg",
-			Data: struct {
-				Sender struct {
-					ID   string `json:"ID"`
-					Name string `json:"Name"`
-				} `json:"sender"`
-				Message string `json:"message"`
-			}{
-				Sender: struct {
-					ID   string `json:"ID"`
-					Name string `json:"Name"`
-				}{
-					ID:   playerID,
-					Name: playerName,
-				},
-				Message: raw,
-			},
-		})
+		// only the drawer and others who found the word can read along
+		msg := ChatMsg{
+			Sender:  ChatSender{ID: playerID, Name: playerName},
+			Message: raw,
+			Channel: ChatChannelGuessed,
+		}
+		for _, pl := range guessedOnly {
+			r.sendWSMessageToPlayer(pl, TypeChatMsg, msg)
+		}
 		return
 	}
 
 	if correct {
+		defer r.flushWS()
+
 		logger.Info("handleGuess: player=%s correct guess broadcast", p.ID)
-		r.BroadcastWS("message", struct {
-			Type string `json:"type"`
-			Data struct {
-				PlayerID   string `json:"playerId"`
-				PlayerName string `json:"playerName"`
-				Message    string `json:"message"`
-			} `json:"data"`
-		}{
-			Type: "correct_guess",
-			Data: struct {
-				PlayerID   string `json:"playerId"`
-				PlayerName string `json:"playerName"`
-				Message    string `json:"message"`
-			}{
-				PlayerID:   playerID,
-				PlayerName: playerName,
-				Message:    playerName + " has guessed the word",
-			},
+		r.BroadcastWS(TypeCorrectGuess, CorrectGuess{
+			PlayerID:   playerID,
+			PlayerName: playerName,
+			Message:    playerName + " has guessed the word",
 		})
 		return
 	}
@@ -198,96 +263,43 @@ func (r *Room) handleGuess(p *Player, wsMsg WSMessage) {
 	if isGuessContext && sendCloseHint {
 		logger.Info("handleGuess: player=%s close guess dist=%d", p.ID, closeDistance)
 
-		type CloseGuess struct {
-			Type string      `json:"type"`
-			Data interface{} `json:"data"`
-		}
-
 		// Full distance only to guesser
-		r.WsMsgTo(p, "message", CloseGuess{
-			Type: "close_guess",
-			Data: struct {
-				PlayerID     string `json:"playerId"`
-				PlayerName   string `json:"playerName"`
-				EditDistance int    `json:"editDistance"`
-				Message      string `json:"message"`
-			}{
-				PlayerID:     playerID,
-				PlayerName:   playerName,
-				EditDistance: closeDistance,
-				Message:      guessLower,
-			},
+		r.sendWSMessageToPlayer(p, TypeCloseGuess, CloseGuess{
+			PlayerID:     playerID,
+			PlayerName:   playerName,
+			EditDistance: closeDistance,
+			Message:      guessLower,
 		})
-		// Masked (0) to others
-		r.BroadcastWSExcept(p, "message", CloseGuess{
-			Type: "close_guess",
-			Data: struct {
-				PlayerID     string `json:"playerId"`
-				PlayerName   string `json:"playerName"`
-				EditDistance int    `json:"editDistance"`
-				Message      string `json:"message"`
-			}{
-				PlayerID:     playerID,
-				PlayerName:   playerName,
-				EditDistance: 0,
-				Message:      guessLower,
-			},
+		// Masked to others, a near miss gives the word away
+		r.BroadcastWSExcept(p, TypeCloseGuess, CloseGuess{
+			PlayerID:   playerID,
+			PlayerName: playerName,
 		})
 		return
 	}
 
 	// Normal chat
 	logger.Info("handleGuess: player=%s normal chat broadcast", p.ID)
-	r.BroadcastWS("message", struct {
-		Type string      `json:"type"`
-		Data interface{} `json:"data"`
-	}{
-		Type: "chat_msg",
-		Data: struct {
-			Sender struct {
-				ID   string `json:"ID"`
-				Name string `json:"Name"`
-			} `json:"sender"`
-			Message string `json:"message"`
-		}{
-			Sender: struct {
-				ID   string `json:"ID"`
-				Name string `json:"Name"`
-			}{
-				ID:   playerID,
-				Name: playerName,
-			},
-			Message: raw,
-		},
+	r.BroadcastWS(TypeChatMsg, ChatMsg{
+		Sender:  ChatSender{ID: playerID, Name: playerName},
+		Message: raw,
+		Channel: ChatChannelAll,
 	})
 }
 
-func (r *Room) WsMsgTo(p *Player, event string, payload any) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return
-	}
-	msgBytes, err := json.Marshal(WSMessage{Type: event, Data: data})
-	if err != nil {
-		return
-	}
-	for _, pl := range r.Players {
-		if pl == p {
-			select {
-			case pl.send <- msgBytes:
-			default:
-			}
-			break
-		}
-	}
-}
-
 func (r *Room) isHost(p *Player) bool {
 	return p != nil && p.ID == r.HostID
 }
 
+// isDrawer reports whether p holds the pencil right now.
+func (r *Room) isDrawer(p *Player) bool {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+	return p != nil && r.Game.Phase == GamePhaseDrawing && p.ID == r.Game.DrawerID
+}
+
 func (r *Room) BroadcastPoint(s *Player, t string, d any) {
-	if !r.isHost(s) {
+	if !r.isDrawer(s) {
 		return
 	}
 	r.BroadcastWSExcept(s, t, d)
@@ -327,6 +339,7 @@ func (r *Room) SendGameState(p *Player) {
 
 	hostID := r.HostID
 	roomID := r.ID
+	settings := r.Settings
 
 	// copy current game state or create default
 	var game *GameState
@@ -336,8 +349,8 @@ func (r *Room) SendGameState(p *Player) {
 	} else {
 		game = &GameState{
 			Phase:          GamePhaseLobby,
-			MaxRounds:      0,
-			Round:          3,
+			MaxRounds:      settings.Rounds,
+			Round:          0,
 			DrawerID:       "",
 			WordMask:       "",
 			WordLen:        "",
@@ -348,15 +361,38 @@ func (r *Room) SendGameState(p *Player) {
 	}
 
 	snapshot := RoomSnapshot{
-		RoomID:  roomID,
-		HostID:  hostID,
-		Players: players,
-		Strokes: strokes,
-		Game:    game,
+		RoomID:   roomID,
+		HostID:   hostID,
+		Players:  players,
+		Strokes:  strokes,
+		Game:     game,
+		Settings: &settings,
 	}
 
 	r.sendWSMessageToPlayer(p, TypeGameState, snapshot)
+	r.resendSecretsLocked(p)
+}
+
+// resendSecretsLocked gives a (re)joining player what the snapshot masks:
+// the word choices or the word for the drawer, the word for players who
+// already guessed it.
+func (r *Room) resendSecretsLocked(p *Player) {
+	g := r.Game
+	if g == nil {
+		return
+	}
 
+	switch {
+	case g.Phase == GamePhaseChoosingWord && p.ID == g.DrawerID:
+		r.sendWSMessageToPlayer(p, TypeWordChoices, WordChoices{
+			Words:    g.choices,
+			Deadline: g.chooseDeadline,
+		})
+	case g.Phase == GamePhaseDrawing && p.ID == g.DrawerID:
+		r.sendWSMessageToPlayer(p, TypeWordChosen, WordChosen{Word: g.word})
+	case g.Phase == GamePhaseDrawing && g.GuessedPlayers[p.ID]:
+		r.sendWSMessageToPlayer(p, TypeHint, Hint{WordMask: g.word})
+	}
 }
 
 func (r *Room) sendWSMessageToPlayer(p *Player, msgType string, data any) {
@@ -387,24 +423,41 @@ func (r *Room) sendWSMessageToPlayer(p *Player, msgType string, data any) {
 	}
 }
 
+// sendError replies to p with a typed error.
+func (r *Room) sendError(p *Player, code, message string) {
+	r.sendWSMessageToPlayer(p, TypeError, ErrorMsg{Code: code, Message: message})
+}
+
 func (r *Room) Run(rm *RoomManager) {
 	defer close(r.done)
 
+	r.startTimer()
+	defer r.stopTimerLoop()
+
 	for {
 		select {
 
 		case player := <-r.Register:
 			r.Mu.Lock()
+			if old, ok := r.Players[player.ID]; ok && old != player {
+				// same player on a new connection, drop the stale one
+				r.saveSessionLocked(old)
+				old.cleanup()
+			}
 			r.Players[player.ID] = player
+			player.Name = sanitizeName(player.Name)
+			r.restoreSessionLocked(player)
+			r.assignNameLocked(player, player.Name)
+			r.migrateHostLocked()
 			r.Mu.Unlock()
 
+			r.flushWSDirect()
+
 			r.SendGameState(player)
 
 			r.Mu.RLock()
-			players := r.Players
+			payload, err := json.Marshal(r.Players)
 			r.Mu.RUnlock()
-
-			payload, err := json.Marshal(players)
 			if err != nil {
 				logger.Error("player struct marshal error")
 			}
@@ -415,34 +468,41 @@ func (r *Room) Run(rm *RoomManager) {
 			}
 
 			if msgbytes, err := json.Marshal(joinedmsg); err == nil {
-				r.Broadcast <- msgbytes
+				r.deliver(Outbound{Payload: msgbytes})
 			}
 
 		case player := <-r.Unregister:
 			r.Mu.Lock()
-			if _, exists := r.Players[player.ID]; exists {
+			if cur, exists := r.Players[player.ID]; exists && cur == player {
 				delete(r.Players, player.ID)
-
-				// clean up empty room
-				if len(r.Players) == 0 {
-					r.Mu.Unlock()
-					rm.Lock()
-					delete(rm.Rooms, r.ID)
-					rm.Unlock()
-					return
+				if r.live != nil && r.live.ownerID == player.ID {
+					r.dropLiveLocked()
 				}
+				r.saveSessionLocked(player)
+				r.queueWS(TypeUserLeft, UserLeft{UserID: player.ID})
+
+				r.migrateHostLocked()
+				r.handlePlayerLeftLocked(player.ID)
 			}
 			r.Mu.Unlock()
 
-		case msg := <-r.Broadcast:
+			r.flushWSDirect()
+
+		case <-r.idle:
+			// clean up the room once every session is past its grace period
 			r.Mu.RLock()
-			for _, p := range r.Players {
-				select {
-				case p.send <- msg:
-				case <-p.ctx.Done():
-				}
-			}
+			abandoned := r.abandonedLocked(time.Now())
 			r.Mu.RUnlock()
+
+			if abandoned {
+				rm.Lock()
+				delete(rm.Rooms, r.ID)
+				rm.Unlock()
+				return
+			}
+
+		case msg := <-r.Broadcast:
+			r.deliver(msg)
 		}
 	}
 }
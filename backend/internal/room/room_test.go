@@ -0,0 +1,48 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFlushKeepsTargetedMessagesInOrder(t *testing.T) {
+	newPlayer := func(id string) *Player {
+		return &Player{ID: id, send: make(chan []byte, 8), ctx: context.Background()}
+	}
+	a, b := newPlayer("a"), newPlayer("b")
+	r := &Room{Players: map[string]*Player{"a": a, "b": b}}
+
+	r.Mu.Lock()
+	r.queueWS(TypeTurnEnd, struct{}{})
+	r.queueWS(TypeClear, struct{}{})
+	r.queueWSTo(b, TypeWordChoices, WordChoices{})
+	r.queueWS(TypeTimer, struct{}{})
+	r.Mu.Unlock()
+	r.flushWSDirect()
+
+	types := func(p *Player) []string {
+		var got []string
+		for len(p.send) > 0 {
+			var msg WSMessage
+			if err := json.Unmarshal(<-p.send, &msg); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, msg.Type)
+		}
+		return got
+	}
+	tests := []struct {
+		p    *Player
+		want []string
+	}{
+		{a, []string{TypeTurnEnd, TypeClear, TypeTimer}},
+		{b, []string{TypeTurnEnd, TypeClear, TypeWordChoices, TypeTimer}},
+	}
+	for _, tt := range tests {
+		if got := types(tt.p); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("player %s got %v, want %v", tt.p.ID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package room
+
+import (
+	"time"
+)
+
+// Guess describes a correct guess for a Scorer.
+type Guess struct {
+	Order    int           // 0 for the first player to find the word
+	TimeLeft time.Duration // until the turn would have timed out
+	DrawTime time.Duration
+	Guessers int // players other than the drawer
+}
+
+// TurnResult describes a finished turn for a Scorer.
+type TurnResult struct {
+	Guessed  int // players that found the word
+	Guessers int
+}
+
+// Scorer decides how many points a turn is worth. Implementations must be
+// pure so they can be reasoned about without a room.
+type Scorer interface {
+	GuessPoints(g Guess) int
+	// DrawerPoints may be negative to penalize a turn nobody guessed.
+	DrawerPoints(t TurnResult) int
+}
+
+// scorers are the strategies RoomSettings.Scoring can name.
+var scorers = map[string]Scorer{
+	"classic": ClassicScorer{},
+	"order":   OrderScorer{Max: 300, Step: 50, Min: 50},
+	"skribbl": SkribblScorer{
+		OrderScorer:  OrderScorer{Max: 300, Step: 50, Min: 50},
+		DrawerMax:    200,
+		NoGuessPoint: -50,
+	},
+}
+
+const defaultScoring = "classic"
+
+// ClassicScorer gives 100 points plus one per second left, drawer gets nothing.
+type ClassicScorer struct{}
+
+func (ClassicScorer) GuessPoints(g Guess) int {
+	return 100 + int(g.TimeLeft/time.Second)
+}
+
+func (ClassicScorer) DrawerPoints(TurnResult) int {
+	return 0
+}
+
+// OrderScorer rewards guessing early: the first guess is worth Max and each
+// later one Step less, never below Min.
+type OrderScorer struct {
+	Max  int
+	Step int
+	Min  int
+}
+
+func (s OrderScorer) GuessPoints(g Guess) int {
+	pts := s.Max - g.Order*s.Step
+	if pts < s.Min {
+		return s.Min
+	}
+	return pts
+}
+
+func (OrderScorer) DrawerPoints(TurnResult) int {
+	return 0
+}
+
+// SkribblScorer scores guesses by order and pays the drawer a share of
+// DrawerMax proportional to how many players guessed. A turn nobody guessed
+// costs the drawer NoGuessPoint.
+type SkribblScorer struct {
+	OrderScorer
+	DrawerMax    int
+	NoGuessPoint int
+}
+
+func (s SkribblScorer) DrawerPoints(t TurnResult) int {
+	if t.Guessers == 0 {
+		return 0
+	}
+	if t.Guessed == 0 {
+		return s.NoGuessPoint
+	}
+	return s.DrawerMax * t.Guessed / t.Guessers
+}
+
+func (r *Room) scorer() Scorer {
+	if s, ok := scorers[r.Settings.Scoring]; ok {
+		return s
+	}
+	return scorers[defaultScoring]
+}
+
+// awardLocked adds pts to a player, online or not. Totals never drop below zero.
+func (r *Room) awardLocked(playerID string, pts int) {
+	var total *int
+	if pl, ok := r.Players[playerID]; ok {
+		total = &pl.Points
+	} else if s, ok := r.Sessions[playerID]; ok {
+		total = &s.Points
+	} else {
+		return
+	}
+
+	r.Game.turnPoints[playerID] += pts
+	*total += pts
+	if *total < 0 {
+		*total = 0
+	}
+}
+
+// scoreGuessLocked awards a correct guess; call it before marking p as guessed.
+func (r *Room) scoreGuessLocked(p *Player) {
+	g := r.Game
+	timeLeft := time.Until(time.Unix(g.EndsAtUnix, 0))
+	if timeLeft < 0 {
+		timeLeft = 0
+	}
+
+	r.awardLocked(p.ID, r.scorer().GuessPoints(Guess{
+		Order:    len(g.GuessedPlayers),
+		TimeLeft: timeLeft,
+		DrawTime: time.Duration(r.Settings.DrawTime) * time.Second,
+		Guessers: len(r.Players) - 1,
+	}))
+}
+
+// scoreDrawerLocked pays the drawer at the end of a drawn turn.
+func (r *Room) scoreDrawerLocked() {
+	g := r.Game
+	if g.Phase != GamePhaseDrawing {
+		return
+	}
+
+	// the same guessers allGuessedLocked waits for, so Guessed never
+	// exceeds Guessers
+	guessers := r.guessersLocked(time.Now())
+	guessed := 0
+	for _, id := range guessers {
+		if g.GuessedPlayers[id] {
+			guessed++
+		}
+	}
+	r.awardLocked(g.DrawerID, r.scorer().DrawerPoints(TurnResult{
+		Guessed:  guessed,
+		Guessers: len(guessers),
+	}))
+}
@@ -0,0 +1,110 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuessPoints(t *testing.T) {
+	skribbl := scorers["skribbl"]
+	tests := []struct {
+		name   string
+		scorer Scorer
+		guess  Guess
+		want   int
+	}{
+		{"classic full time", ClassicScorer{}, Guess{TimeLeft: 80 * time.Second}, 180},
+		{"classic rounds down", ClassicScorer{}, Guess{TimeLeft: 1500 * time.Millisecond}, 101},
+		{"classic no time left", ClassicScorer{}, Guess{}, 100},
+		{"order first", OrderScorer{Max: 300, Step: 50, Min: 50}, Guess{Order: 0}, 300},
+		{"order third", OrderScorer{Max: 300, Step: 50, Min: 50}, Guess{Order: 2}, 200},
+		{"order floor", OrderScorer{Max: 300, Step: 50, Min: 50}, Guess{Order: 10}, 50},
+		{"skribbl uses order", skribbl, Guess{Order: 1}, 250},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scorer.GuessPoints(tt.guess); got != tt.want {
+				t.Errorf("GuessPoints(%+v) = %d, want %d", tt.guess, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawerPoints(t *testing.T) {
+	skribbl := scorers["skribbl"]
+	tests := []struct {
+		name   string
+		scorer Scorer
+		result TurnResult
+		want   int
+	}{
+		{"classic", ClassicScorer{}, TurnResult{Guessed: 3, Guessers: 3}, 0},
+		{"order", OrderScorer{Max: 300, Step: 50, Min: 50}, TurnResult{Guessed: 3, Guessers: 3}, 0},
+		{"skribbl everyone", skribbl, TurnResult{Guessed: 4, Guessers: 4}, 200},
+		{"skribbl half", skribbl, TurnResult{Guessed: 2, Guessers: 4}, 100},
+		{"skribbl nobody", skribbl, TurnResult{Guessed: 0, Guessers: 4}, -50},
+		{"skribbl alone", skribbl, TurnResult{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scorer.DrawerPoints(tt.result); got != tt.want {
+				t.Errorf("DrawerPoints(%+v) = %d, want %d", tt.result, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreDrawerCountsGuessersWhoLeft(t *testing.T) {
+	r := &Room{
+		Players:  map[string]*Player{"drawer": {ID: "drawer"}, "b": {ID: "b"}},
+		Sessions: map[string]*PlayerSession{},
+		Game:     newGameState(1),
+		Settings: defaultSettings(),
+	}
+	r.Settings.Scoring = "skribbl"
+	g := r.Game
+	g.Phase = GamePhaseDrawing
+	g.DrawerID = "drawer"
+	g.turnPoints = make(map[string]int)
+	// a and c guessed, then a left; b is still guessing
+	g.GuessedPlayers = map[string]bool{"a": true, "c": true}
+	r.Players["c"] = &Player{ID: "c"}
+
+	r.scoreDrawerLocked()
+
+	if got := r.Players["drawer"].Points; got != 200*2/3 {
+		t.Errorf("drawer got %d points, want %d", got, 200*2/3)
+	}
+}
+
+func TestAllGuessedWaitsForRejoiningPlayers(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		offline time.Time // when guesser b went offline
+		guessed bool
+		want    bool
+	}{
+		{"reloading, not guessed", now.Add(-time.Second), false, false},
+		{"reloading, guessed", now.Add(-time.Second), true, true},
+		{"gone past grace, nobody guessed", now.Add(-2 * rejoinGrace), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Room{
+				Players: map[string]*Player{"drawer": {ID: "drawer"}},
+				Sessions: map[string]*PlayerSession{
+					"b": {ID: "b", offlineSince: tt.offline},
+				},
+				Game: newGameState(1),
+			}
+			r.Game.Phase = GamePhaseDrawing
+			r.Game.DrawerID = "drawer"
+			r.Game.GuessedPlayers = map[string]bool{"b": tt.guessed}
+
+			if got := r.allGuessedLocked(); got != tt.want {
+				t.Errorf("allGuessedLocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package room
+
+import (
+	"time"
+
+	"github.com/sakshamg567/doodlz/backend/logger"
+)
+
+// how long an offline player keeps their seat, points and guessed status
+const rejoinGrace = 60 * time.Second
+
+// saveSessionLocked marks p's session offline, keeping what a rejoin needs.
+func (r *Room) saveSessionLocked(p *Player) {
+	s, ok := r.Sessions[p.ID]
+	if !ok {
+		s = &PlayerSession{ID: p.ID}
+		r.Sessions[p.ID] = s
+	}
+	s.Name = p.Name
+	s.Points = p.Points
+	s.Guessed = r.Game.GuessedPlayers[p.ID]
+	s.Online = false
+	s.offlineSince = time.Now()
+}
+
+// restoreSessionLocked brings back a recent session for a player that
+// registers again, or starts a fresh one.
+func (r *Room) restoreSessionLocked(p *Player) {
+	joinedAt := time.Now()
+
+	s, ok := r.Sessions[p.ID]
+	if ok && (s.Online || time.Since(s.offlineSince) <= rejoinGrace) {
+		joinedAt = s.joinedAt
+	}
+	if ok && !s.Online && time.Since(s.offlineSince) <= rejoinGrace {
+		p.Points = s.Points
+		if p.Name == "" {
+			p.Name = s.Name
+		}
+		if s.Guessed {
+			r.Game.GuessedPlayers[p.ID] = true
+		}
+	}
+
+	r.Sessions[p.ID] = &PlayerSession{
+		ID:      p.ID,
+		Name:    p.Name,
+		Points:  p.Points,
+		Online:  true,
+		Guessed: r.Game.GuessedPlayers[p.ID],
+
+		joinedAt: joinedAt,
+	}
+}
+
+// migrateHostLocked hands the room to the longest connected online player
+// once the host's session has gone offline. Ties go to the lower player ID.
+// A host that has not connected yet keeps the room.
+func (r *Room) migrateHostLocked() {
+	if s, ok := r.Sessions[r.HostID]; !ok || s.Online {
+		return
+	}
+
+	var next *PlayerSession
+	for id := range r.Players {
+		s, ok := r.Sessions[id]
+		if !ok {
+			continue
+		}
+		if next == nil ||
+			s.joinedAt.Before(next.joinedAt) ||
+			(s.joinedAt.Equal(next.joinedAt) && s.ID < next.ID) {
+			next = s
+		}
+	}
+	if next == nil {
+		return
+	}
+
+	logger.Info("room %s: host %s -> %s", r.ID, r.HostID, next.ID)
+	r.HostID = next.ID
+	r.queueWS(TypeHostChanged, HostChanged{HostID: next.ID})
+}
+
+// isPresentLocked reports whether id is online or still within its grace period.
+func (r *Room) isPresentLocked(id string, now time.Time) bool {
+	if _, online := r.Players[id]; online {
+		return true
+	}
+	s, ok := r.Sessions[id]
+	return ok && (s.Online || now.Sub(s.offlineSince) <= rejoinGrace)
+}
+
+// presentLocked counts players that are online or still within their grace period.
+func (r *Room) presentLocked(now time.Time) int {
+	n := 0
+	for _, s := range r.Sessions {
+		if s.Online || now.Sub(s.offlineSince) <= rejoinGrace {
+			n++
+		}
+	}
+	return n
+}
+
+// abandonedLocked reports whether nobody is left who could still rejoin.
+// A new room gets the same grace period for its first player to show up.
+func (r *Room) abandonedLocked(now time.Time) bool {
+	if now.Sub(r.createdAt) <= rejoinGrace {
+		return false
+	}
+	return len(r.Players) == 0 && r.presentLocked(now) == 0
+}
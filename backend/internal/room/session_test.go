@@ -0,0 +1,40 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrateHost(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		host *PlayerSession // the host's session, nil if they never connected
+		want string
+	}{
+		{"host not connected yet", nil, "host"},
+		{"host online", &PlayerSession{ID: "host", Online: true}, "host"},
+		{"host offline", &PlayerSession{ID: "host", offlineSince: now}, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Room{
+				HostID:   "host",
+				Players:  map[string]*Player{"b": {ID: "b"}},
+				Sessions: map[string]*PlayerSession{"b": {ID: "b", Online: true, joinedAt: now}},
+			}
+			if tt.host != nil {
+				r.Sessions["host"] = tt.host
+				if tt.host.Online {
+					r.Players["host"] = &Player{ID: "host"}
+				}
+			}
+
+			r.migrateHostLocked()
+
+			if r.HostID != tt.want {
+				t.Errorf("HostID = %q, want %q", r.HostID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sakshamg567/doodlz/backend/logger"
+	"github.com/sakshamg567/doodlz/backend/pkg/utils"
+)
+
+// RoomSettings are negotiated in the lobby and fixed once a game starts.
+type RoomSettings struct {
+	MaxPlayers  int       `json:"maxPlayers"`
+	Rounds      int       `json:"rounds"`
+	DrawTime    int       `json:"drawTime"` // seconds
+	WordChoices int       `json:"wordChoices"`
+	Hints       int       `json:"hints"`
+	HintAt      []float64 `json:"hintAt,omitempty"` // fractions of drawTime, overrides the halving schedule of Hints
+	Language    string    `json:"language"`
+	WordPacks   []string  `json:"wordPacks,omitempty"` // empty means every pack in Language
+	WordMix     WordMix   `json:"wordMix"`
+	Private     bool      `json:"private"`
+	CustomOnly  bool      `json:"customOnly"`
+	CustomRatio float64   `json:"customRatio"` // share of words taken from the custom list
+	LobbyDelay  int       `json:"lobbyDelay"`  // seconds on the game_end screen
+	Scoring     string    `json:"scoring"`     // name of a Scorer in scorers
+
+	PluralTolerant bool `json:"pluralTolerant"` // accept "cats" for "cat" and back
+
+	// filled in by the server
+	CustomWordCount int `json:"customWordCount"`
+}
+
+func defaultSettings() RoomSettings {
+	return RoomSettings{
+		MaxPlayers:  8,
+		Rounds:      3,
+		DrawTime:    80,
+		WordChoices: 3,
+		Hints:       2,
+		Language:    "en",
+		WordMix:     defaultWordMix,
+		LobbyDelay:  10,
+		Scoring:     defaultScoring,
+	}
+}
+
+// hintFractions is HintAt if set, the halving schedule for Hints otherwise.
+func (s RoomSettings) hintFractions() []float64 {
+	if len(s.HintAt) > 0 {
+		return s.HintAt
+	}
+	return hintFractions(s.Hints)
+}
+
+func (s RoomSettings) validate() error {
+	switch {
+	case s.MaxPlayers < minPlayers || s.MaxPlayers > 16:
+		return fmt.Errorf("maxPlayers must be between %d and 16", minPlayers)
+	case s.Rounds < 1 || s.Rounds > 10:
+		return errors.New("rounds must be between 1 and 10")
+	case s.DrawTime < 30 || s.DrawTime > 240:
+		return errors.New("drawTime must be between 30 and 240 seconds")
+	case s.WordChoices < 1 || s.WordChoices > 5:
+		return errors.New("wordChoices must be between 1 and 5")
+	case s.Hints < 0 || s.Hints > 5:
+		return errors.New("hints must be between 0 and 5")
+	case len(s.HintAt) > 5:
+		return errors.New("hintAt takes at most 5 fractions")
+	case !s.WordMix.valid():
+		return errors.New("wordMix weights must not be negative")
+	case s.CustomRatio < 0 || s.CustomRatio > 1:
+		return errors.New("customRatio must be between 0 and 1")
+	case s.LobbyDelay < 0 || s.LobbyDelay > 120:
+		return errors.New("lobbyDelay must be between 0 and 120 seconds")
+	}
+
+	for i, f := range s.HintAt {
+		if f <= 0 || f >= 1 || (i > 0 && f <= s.HintAt[i-1]) {
+			return errors.New("hintAt must be increasing fractions between 0 and 1")
+		}
+	}
+	if _, ok := scorers[s.Scoring]; !ok {
+		return fmt.Errorf("unknown scoring %q", s.Scoring)
+	}
+	if s.Language == "" {
+		return errors.New("language is required")
+	}
+	if err := utils.CheckPacks(s.packs()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// packs returns the word pack selectors the room draws from.
+func (s RoomSettings) packs() []string {
+	if len(s.WordPacks) > 0 {
+		return s.WordPacks
+	}
+	return []string{s.Language}
+}
+
+// settingsUpdate is a settings change; fields that are left out keep their
+// current value. CustomWords replaces the custom list when present.
+type settingsUpdate struct {
+	RoomSettings
+	CustomWords *[]string `json:"customWords"`
+}
+
+// applySettingsLocked merges raw into the room settings. Nothing is changed
+// if the result does not validate. Rejected custom words are returned.
+func (r *Room) applySettingsLocked(raw []byte) ([]string, error) {
+	upd := settingsUpdate{RoomSettings: r.Settings}
+	upd.WordPacks = append([]string(nil), r.Settings.WordPacks...)
+	upd.HintAt = append([]float64(nil), r.Settings.HintAt...)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &upd); err != nil {
+			return nil, err
+		}
+	}
+
+	next := upd.RoomSettings
+	if err := next.validate(); err != nil {
+		return nil, err
+	}
+
+	words := r.CustomWords
+	var rejected []string
+	if upd.CustomWords != nil {
+		words, rejected = newCustomWords(*upd.CustomWords)
+	}
+	if next.CustomOnly && len(words) == 0 {
+		return rejected, errors.New("customOnly needs at least one accepted custom word")
+	}
+
+	r.CustomWords = words
+	next.CustomWordCount = len(words)
+	r.Settings = next
+	r.Game.MaxRounds = next.Rounds
+	return rejected, nil
+}
+
+func (r *Room) handleUpdateSettings(p *Player, raw json.RawMessage) {
+	r.Mu.Lock()
+	defer func() {
+		r.Mu.Unlock()
+		r.flushWS()
+	}()
+
+	if !r.isHost(p) {
+		r.sendError(p, ErrCodeNotHost, "only the host can change settings")
+		return
+	}
+	if r.Game.Phase != GamePhaseLobby {
+		r.sendError(p, ErrCodeWrongPhase, "settings can only be changed in the lobby")
+		return
+	}
+
+	rejected, err := r.applySettingsLocked(raw)
+	if err != nil {
+		logger.Info("handleUpdateSettings: player=%s rejected err=%v", p.ID, err)
+		r.sendError(p, ErrCodeInvalidSettings, err.Error())
+		return
+	}
+
+	if len(rejected) > 0 {
+		r.sendWSMessageToPlayer(p, TypeCustomWords, CustomWordsResult{
+			Accepted: len(r.CustomWords),
+			Rejected: rejected,
+		})
+	}
+	r.queueWS(TypeSettings, r.Settings)
+}
+
+// CanJoin reports whether playerID may connect: returning players always
+// can, new ones only while there is a free seat.
+func (r *Room) CanJoin(playerID string) bool {
+	r.Mu.RLock()
+	defer r.Mu.RUnlock()
+
+	if _, ok := r.Players[playerID]; ok {
+		return true
+	}
+	if s, ok := r.Sessions[playerID]; ok && !s.Online && time.Since(s.offlineSince) <= rejoinGrace {
+		return true
+	}
+	return len(r.Players) < r.Settings.MaxPlayers
+}
@@ -0,0 +1,162 @@
+package room
+
+import (
+	"time"
+)
+
+// live strokes are fanned out at this rate, however often the drawer sends
+const streamInterval = time.Second / 30
+
+// liveStroke is the stroke the drawer is in the middle of. It only becomes
+// part of Room.Strokes on stroke_end. While it is live, streamLive fans its
+// points out; rooms with no stroke in progress do not tick at all.
+type liveStroke struct {
+	ownerID   string
+	startedAt time.Time
+	stroke    Stroke        // style and every point received so far
+	pending   []Point       // received since the last stream tick
+	done      chan struct{} // closed by endLiveLocked
+}
+
+type StrokeBeginIn struct {
+	StrokeColor string  `json:"strokeColor"`
+	StrokeWidth float64 `json:"strokeWidth"`
+}
+
+type StrokePointsIn struct {
+	Points []Point `json:"points"`
+}
+
+type StrokeEndIn struct{}
+
+func (r *Room) handleStrokeBegin(p *Player, in StrokeBeginIn) {
+	color, serr := checkStyle(in.StrokeColor, in.StrokeWidth)
+	if serr != nil {
+		r.rejectStroke(p, serr)
+		return
+	}
+
+	width := strokeWidth(in.StrokeWidth)
+
+	r.Mu.Lock()
+	r.dropLiveLocked()
+	if r.roomPointsLocked() >= maxRoomPoints {
+		r.Mu.Unlock()
+		r.rejectStroke(p, errStrokeBudget())
+		return
+	}
+
+	seq, id := r.nextStrokeIDLocked()
+	r.live = &liveStroke{
+		ownerID:   p.ID,
+		startedAt: time.Now(),
+		stroke:    Stroke{ID: id, Seq: seq, StrokeColor: color, StrokeWidth: width},
+		done:      make(chan struct{}),
+	}
+	go r.streamLive(r.live)
+	r.queueWSFor(CapStrokeStreaming, TypeStrokeBegin, StrokeBegin{
+		StrokeID:    id,
+		PlayerID:    p.ID,
+		StrokeColor: color,
+		StrokeWidth: width,
+	})
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+// handleStrokePoints buffers points for the next stream tick. Points that
+// arrive without a stroke in progress, e.g. right after a clear, are dropped.
+func (r *Room) handleStrokePoints(p *Player, in StrokePointsIn) {
+	pts, serr := sanitizePoints(in.Points)
+	if serr != nil {
+		r.rejectStroke(p, serr)
+		return
+	}
+
+	r.Mu.Lock()
+	live := r.live
+	switch {
+	case live == nil || live.ownerID != p.ID:
+	case len(live.stroke.Paths)+len(pts) > maxStrokePoints:
+		serr = invalidStroke("stroke has more than %d points", maxStrokePoints)
+	case r.roomPointsLocked()+len(live.stroke.Paths)+len(pts) > maxRoomPoints:
+		serr = errStrokeBudget()
+	default:
+		live.stroke.Paths = append(live.stroke.Paths, pts...)
+		live.pending = append(live.pending, pts...)
+	}
+	r.Mu.Unlock()
+
+	if serr != nil {
+		r.rejectStroke(p, serr)
+	}
+}
+
+// handleStrokeEnd commits the live stroke. The last buffered points go out
+// first so guessers see the line finish before it is committed.
+func (r *Room) handleStrokeEnd(p *Player, _ StrokeEndIn) {
+	r.Mu.Lock()
+	if live := r.live; live != nil && live.ownerID == p.ID {
+		r.endLiveLocked()
+		r.queuePendingLocked(live)
+		if len(live.stroke.Paths) == 0 {
+			r.queueWSFor(CapStrokeStreaming, TypeStrokeCancel, StrokeRemoved{StrokeID: live.stroke.ID})
+		} else {
+			r.addStrokeLocked(live.stroke, live.startedAt)
+		}
+	}
+	r.Mu.Unlock()
+
+	r.flushWS()
+}
+
+// streamLive sends out live's buffered points every streamInterval until
+// live stops being the room's stroke in progress.
+func (r *Room) streamLive(live *liveStroke) {
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-live.done:
+			return
+		case <-ticker.C:
+			r.Mu.Lock()
+			// the stroke may have ended between the tick and the lock
+			if r.live == live {
+				r.queuePendingLocked(live)
+			}
+			r.Mu.Unlock()
+
+			r.flushWS()
+		}
+	}
+}
+
+func (r *Room) queuePendingLocked(live *liveStroke) {
+	if len(live.pending) == 0 {
+		return
+	}
+	r.queueWSFor(CapStrokeStreaming, TypeStrokePoints, StrokePoints{StrokeID: live.stroke.ID, Points: live.pending})
+	live.pending = nil
+}
+
+// dropLiveLocked throws away a half finished stroke and tells clients to
+// forget what they drew of it.
+func (r *Room) dropLiveLocked() {
+	if r.live == nil {
+		return
+	}
+	r.queueWSFor(CapStrokeStreaming, TypeStrokeCancel, StrokeRemoved{StrokeID: r.live.stroke.ID})
+	r.endLiveLocked()
+}
+
+// endLiveLocked clears the stroke in progress, if any, and stops its stream.
+func (r *Room) endLiveLocked() {
+	if r.live == nil {
+		return
+	}
+	close(r.live.done)
+	r.live = nil
+}
@@ -0,0 +1,193 @@
+package room
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Drawing coordinates live in a normalized canvas space: (0,0) is the top
+// left corner and (1,1) the bottom right, whatever the client's pixel size.
+const (
+	canvasMin = 0.0
+	canvasMax = 1.0
+
+	minStrokeWidth = 1
+	maxStrokeWidth = 40
+
+	maxStrokePoints = 2000  // per path
+	maxRoomPoints   = 50000 // across every stroke on the canvas
+)
+
+// palette names accepted in place of a hex color
+var palette = map[string]string{
+	"white":  "#ffffff",
+	"black":  "#000000",
+	"gray":   "#808080",
+	"red":    "#ef130b",
+	"orange": "#ff7100",
+	"yellow": "#ffe400",
+	"green":  "#00cc00",
+	"blue":   "#00b2ff",
+	"purple": "#a300ba",
+	"pink":   "#df69a7",
+	"brown":  "#a0522d",
+}
+
+// StrokeError is why a drawing message was refused. Code is sent back to
+// the drawer with TypeError.
+type StrokeError struct {
+	Code   string
+	Reason string
+}
+
+func (e *StrokeError) Error() string {
+	return e.Code + ": " + e.Reason
+}
+
+func invalidStroke(format string, args ...any) *StrokeError {
+	return &StrokeError{Code: ErrCodeInvalidStroke, Reason: fmt.Sprintf(format, args...)}
+}
+
+func errStrokeBudget() *StrokeError {
+	return &StrokeError{Code: ErrCodeStrokeBudget, Reason: "the canvas is full, clear or undo to keep drawing"}
+}
+
+// normalizeColor returns c as lowercase "#rrggbb". It accepts "#rgb",
+// "#rrggbb" and the names in palette.
+func normalizeColor(c string) (string, bool) {
+	c = strings.ToLower(strings.TrimSpace(c))
+	if hex, ok := palette[c]; ok {
+		return hex, true
+	}
+	if !strings.HasPrefix(c, "#") || (len(c) != 4 && len(c) != 7) {
+		return "", false
+	}
+	for _, d := range c[1:] {
+		if !strings.ContainsRune("0123456789abcdef", d) {
+			return "", false
+		}
+	}
+	if len(c) == 4 {
+		c = string([]byte{'#', c[1], c[1], c[2], c[2], c[3], c[3]})
+	}
+	return c, true
+}
+
+// clampCoord pins v into the canvas space. NaN and infinities are refused.
+func clampCoord(v float64) (float64, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return math.Max(canvasMin, math.Min(canvasMax, v)), true
+}
+
+func validWidth(w float64) bool {
+	return w >= minStrokeWidth && w <= maxStrokeWidth
+}
+
+// strokeWidth is a width validWidth accepted as strokes store it.
+func strokeWidth(w float64) int8 {
+	return int8(math.Round(w))
+}
+
+func validPointType(t string) bool {
+	switch t {
+	case "", "start", "move", "end":
+		return true
+	}
+	return false
+}
+
+// sanitizeStroke checks s and returns it as a Stroke with coordinates
+// clamped and colors normalized.
+func sanitizeStroke(s StrokeIn) (Stroke, *StrokeError) {
+	color, serr := checkStyle(s.StrokeColor, s.StrokeWidth)
+	if serr != nil {
+		return Stroke{}, serr
+	}
+	if len(s.Paths) == 0 {
+		return Stroke{}, invalidStroke("stroke has no points")
+	}
+	if len(s.Paths) > maxStrokePoints {
+		return Stroke{}, invalidStroke("stroke has %d points, at most %d allowed", len(s.Paths), maxStrokePoints)
+	}
+
+	paths, serr := sanitizePoints(s.Paths)
+	if serr != nil {
+		return Stroke{}, serr
+	}
+	return Stroke{StrokeColor: color, StrokeWidth: strokeWidth(s.StrokeWidth), Paths: paths}, nil
+}
+
+// checkStyle validates a stroke's color and width and returns the color
+// normalized.
+func checkStyle(color string, width float64) (string, *StrokeError) {
+	c, ok := normalizeColor(color)
+	if !ok {
+		return "", invalidStroke("color %q is not a hex or palette color", color)
+	}
+	if !validWidth(width) {
+		return "", invalidStroke("width must be between %d and %d", minStrokeWidth, maxStrokeWidth)
+	}
+	return c, nil
+}
+
+// sanitizePoints returns a clamped copy of pts.
+func sanitizePoints(pts []Point) ([]Point, *StrokeError) {
+	out := make([]Point, len(pts))
+	for i, pt := range pts {
+		x, okX := clampCoord(pt.X)
+		y, okY := clampCoord(pt.Y)
+		if !okX || !okY {
+			return nil, invalidStroke("point %d is not a number", i)
+		}
+		if !validPointType(pt.Type) {
+			return nil, invalidStroke("point %d has unknown type %q", i, pt.Type)
+		}
+		if pt.Color != "" {
+			var ok bool
+			if pt.Color, ok = normalizeColor(pt.Color); !ok {
+				return nil, invalidStroke("point %d color is not a hex or palette color", i)
+			}
+		}
+		out[i] = Point{X: x, Y: y, Color: pt.Color, Type: pt.Type}
+	}
+	return out, nil
+}
+
+// sanitizeDrawPoint is sanitizeStroke for a single live point.
+func sanitizeDrawPoint(pt DrawPoint) (DrawPoint, *StrokeError) {
+	if pt.Type != "start" && pt.Type != "move" && pt.Type != "end" {
+		return pt, invalidStroke("unknown point type %q", pt.Type)
+	}
+	color, serr := checkStyle(pt.PointColor, pt.PointSize)
+	if serr != nil {
+		return pt, serr
+	}
+	x, okX := clampCoord(pt.X)
+	y, okY := clampCoord(pt.Y)
+	if !okX || !okY {
+		return pt, invalidStroke("point is not a number")
+	}
+
+	pt.X, pt.Y, pt.PointColor = x, y, color
+	return pt, nil
+}
+
+// roomPointsLocked counts the points of every stroke on the canvas.
+func (r *Room) roomPointsLocked() int {
+	n := 0
+	for _, s := range r.Strokes {
+		n += len(s.Paths)
+	}
+	return n
+}
+
+// checkBudgetLocked refuses a stroke that would take the canvas past maxRoomPoints.
+func (r *Room) checkBudgetLocked(s Stroke) *StrokeError {
+	if r.roomPointsLocked()+len(s.Paths) > maxRoomPoints {
+		return errStrokeBudget()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package room
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"#ff0000", "#ff0000", true},
+		{"#F00", "#ff0000", true},
+		{" #AbCdEf ", "#abcdef", true},
+		{"red", palette["red"], true},
+		{"#ff000", "", false},
+		{"#gg0000", "", false},
+		{"ff0000", "", false},
+		{"rgb(0,0,0)", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeColor(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeColor(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSanitizeStroke(t *testing.T) {
+	line := []Point{{X: 0.1, Y: 0.1}, {X: 0.2, Y: 0.2}}
+	tests := []struct {
+		name  string
+		in    StrokeIn
+		code  string // empty when the stroke is accepted
+		width int8
+		paths []Point
+	}{
+		{"ok", StrokeIn{StrokeColor: "#000", StrokeWidth: 4, Paths: line}, "", 4, line},
+		{"thinnest", StrokeIn{StrokeColor: "#000", StrokeWidth: minStrokeWidth, Paths: line}, "", minStrokeWidth, line},
+		{"widest", StrokeIn{StrokeColor: "#000", StrokeWidth: maxStrokeWidth, Paths: line}, "", maxStrokeWidth, line},
+		{"too thin", StrokeIn{StrokeColor: "#000", StrokeWidth: 0.5, Paths: line}, ErrCodeInvalidStroke, 0, nil},
+		{"too wide", StrokeIn{StrokeColor: "#000", StrokeWidth: maxStrokeWidth + 1, Paths: line}, ErrCodeInvalidStroke, 0, nil},
+		{"wider than int8", StrokeIn{StrokeColor: "#000", StrokeWidth: 200, Paths: line}, ErrCodeInvalidStroke, 0, nil},
+		{"bad color", StrokeIn{StrokeColor: "nope", StrokeWidth: 4, Paths: line}, ErrCodeInvalidStroke, 0, nil},
+		{"no points", StrokeIn{StrokeColor: "#000", StrokeWidth: 4}, ErrCodeInvalidStroke, 0, nil},
+		{"too many points", StrokeIn{StrokeColor: "#000", StrokeWidth: 4, Paths: make([]Point, maxStrokePoints+1)}, ErrCodeInvalidStroke, 0, nil},
+		{"clamped", StrokeIn{StrokeColor: "#000", StrokeWidth: 4, Paths: []Point{{X: -1, Y: 2}}}, "", 4, []Point{{X: canvasMin, Y: canvasMax}}},
+		{"nan", StrokeIn{StrokeColor: "#000", StrokeWidth: 4, Paths: []Point{{X: math.NaN(), Y: 0}}}, ErrCodeInvalidStroke, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, serr := sanitizeStroke(tt.in)
+			switch {
+			case tt.code != "":
+				if serr == nil || serr.Code != tt.code {
+					t.Fatalf("sanitizeStroke error = %v, want code %s", serr, tt.code)
+				}
+			case serr != nil:
+				t.Fatalf("sanitizeStroke error = %v, want none", serr)
+			case got.StrokeWidth != tt.width || len(got.Paths) != len(tt.paths):
+				t.Fatalf("sanitizeStroke = %+v, want width %d and %d points", got, tt.width, len(tt.paths))
+			default:
+				for i := range tt.paths {
+					if got.Paths[i].X != tt.paths[i].X || got.Paths[i].Y != tt.paths[i].Y {
+						t.Errorf("point %d = %+v, want %+v", i, got.Paths[i], tt.paths[i])
+					}
+				}
+			}
+		})
+	}
+}
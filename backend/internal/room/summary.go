@@ -0,0 +1,80 @@
+package room
+
+import (
+	"sort"
+	"time"
+)
+
+// turnSummaryLocked lists what every player earned in the turn that is ending.
+func (r *Room) turnSummaryLocked(reason string) TurnEnd {
+	g := r.Game
+	scores := make([]TurnScore, 0, len(r.Players))
+	for _, st := range r.standingsLocked() {
+		scores = append(scores, TurnScore{
+			PlayerID: st.PlayerID,
+			Name:     st.Name,
+			Points:   g.turnPoints[st.PlayerID],
+			Total:    st.Points,
+		})
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Points > scores[j].Points
+	})
+
+	return TurnEnd{
+		Word:     g.word,
+		DrawerID: g.DrawerID,
+		Reason:   reason,
+		Scores:   scores,
+	}
+}
+
+// gameSummaryLocked ranks everyone who played. Equal scores share a rank and
+// every player on rank one is a winner.
+func (r *Room) gameSummaryLocked() GameEnd {
+	ranking := r.standingsLocked()
+
+	var winners []string
+	for i := range ranking {
+		if i > 0 && ranking[i].Points == ranking[i-1].Points {
+			ranking[i].Rank = ranking[i-1].Rank
+		} else {
+			ranking[i].Rank = i + 1
+		}
+		if ranking[i].Rank == 1 {
+			winners = append(winners, ranking[i].PlayerID)
+		}
+	}
+
+	return GameEnd{
+		Ranking: ranking,
+		Winners: winners,
+		LobbyAt: time.Now().Add(time.Duration(r.Settings.LobbyDelay) * time.Second).Unix(),
+		Replays: r.replaysLocked(),
+	}
+}
+
+// standingsLocked returns online players and those still within their rejoin
+// grace period, highest score first.
+func (r *Room) standingsLocked() []Standing {
+	now := time.Now()
+	out := make([]Standing, 0, len(r.Sessions))
+	for id, s := range r.Sessions {
+		st := Standing{PlayerID: id, Name: s.Name, Points: s.Points}
+		if pl, ok := r.Players[id]; ok {
+			st.Name = pl.Name
+			st.Points = pl.Points
+		} else if now.Sub(s.offlineSince) > rejoinGrace {
+			continue
+		}
+		out = append(out, st)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
+		return out[i].PlayerID < out[j].PlayerID
+	})
+	return out
+}
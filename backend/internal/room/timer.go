@@ -0,0 +1,87 @@
+package room
+
+import (
+	"time"
+)
+
+const timerInterval = time.Second
+
+// startTimer spins up the room clock. It drives every phase deadline and
+// sends a "timer" tick to the room once a second while someone is drawing.
+func (r *Room) startTimer() {
+	r.Mu.Lock()
+	r.timerTicker = time.NewTicker(timerInterval)
+	r.stopTimer = make(chan struct{})
+	ticker, stop := r.timerTicker, r.stopTimer
+	r.Mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				r.tick(now)
+			}
+		}
+	}()
+}
+
+func (r *Room) stopTimerLoop() {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+
+	if r.timerTicker != nil {
+		r.timerTicker.Stop()
+		r.timerTicker = nil
+	}
+	r.endLiveLocked()
+	if r.stopTimer != nil {
+		close(r.stopTimer)
+		r.stopTimer = nil
+	}
+}
+
+func (r *Room) tick(now time.Time) {
+	r.Mu.Lock()
+
+	g := r.Game
+	switch {
+	case g.Phase != GamePhaseLobby && g.Phase != GamePhaseGameEnd && r.presentLocked(now) < minPlayers:
+		r.stopGameLocked()
+
+	case !r.deadline.IsZero() && !now.Before(r.deadline):
+		fn := r.onDeadline
+		r.deadline = time.Time{}
+		r.onDeadline = nil
+		if fn != nil {
+			fn()
+		}
+
+	case r.allGuessedLocked():
+		r.endTurnLocked(TurnEndAllGuessed)
+
+	case g.Phase == GamePhaseDrawing:
+		r.revealHintLocked(now)
+
+		remaining := g.EndsAtUnix - now.Unix()
+		if remaining < 0 {
+			remaining = 0
+		}
+		r.queueWS(TypeTimer, TimerTick{
+			Phase:     g.Phase,
+			EndsAt:    g.EndsAtUnix,
+			Remaining: remaining,
+		})
+	}
+
+	if r.abandonedLocked(now) {
+		select {
+		case r.idle <- struct{}{}:
+		default:
+		}
+	}
+
+	r.Mu.Unlock()
+	r.flushWS()
+}
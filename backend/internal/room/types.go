@@ -1,6 +1,9 @@
 package room
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 const (
 	GamePhaseLobby        = "lobby"
@@ -9,8 +12,49 @@ const (
 	GamePhaseRoundEnd     = "round_end"
 	GamePhaseGameEnd      = "game_end"
 
-	TypeGameState  = "game_state"
-	TypeUserJoined = "user_joined"
+	TypeGameState     = "game_state"
+	TypeUserJoined    = "user_joined"
+	TypeUserLeft      = "user_left"
+	TypeHostChanged   = "host_changed"
+	TypePlayerRenamed = "player_renamed"
+	TypePhaseChange   = "phase_change"
+	TypeTimer         = "timer"
+	TypeWordChoices   = "word_choices"
+	TypeWordChosen    = "word_chosen"
+	TypeHint          = "hint"
+	TypeCustomWords   = "custom_words"
+	TypeSettings      = "settings"
+	TypeError         = "error"
+	TypeTurnEnd       = "turn_end"
+	TypeGameEnd       = "game_end"
+
+	TypeChatMsg      = "chat_msg"
+	TypeCorrectGuess = "correct_guess"
+	TypeCloseGuess   = "close_guess"
+	TypeDrawPoint    = "draw_point"
+	TypeStroke       = "stroke"
+	TypeClear        = "clear"
+	TypeUndo         = "undo"
+	TypeRedo         = "redo"
+	TypeStrokeBegin  = "stroke_begin"
+	TypeStrokePoints = "stroke_points"
+	TypeStrokeCancel = "stroke_cancel"
+)
+
+// error codes sent with TypeError
+const (
+	ErrCodeNotHost          = "not_host"
+	ErrCodeWrongPhase       = "wrong_phase"
+	ErrCodeInvalidSettings  = "invalid_settings"
+	ErrCodeRoomFull         = "room_full"
+	ErrCodeNotEnoughPlayers = "not_enough_players"
+	ErrCodeWordLeak         = "word_leak"
+	ErrCodeUnknownType      = "unknown_type"
+	ErrCodeBadPayload       = "bad_payload"
+	ErrCodeInvalidName      = "invalid_name"
+	ErrCodeInvalidStroke    = "invalid_stroke"
+	ErrCodeStrokeBudget     = "stroke_budget"
+	ErrCodeUnknownStroke    = "unknown_stroke"
 )
 
 type GameState struct {
@@ -25,16 +69,123 @@ type GameState struct {
 	GuessedPlayers map[string]bool `json:"-"`
 
 	// internal
-	word           string `json:"-"`
-	chooseDeadline int64  `json:"-"`
+	word           string         `json:"-"`
+	chooseDeadline int64          `json:"-"`
+	drawQueue      []string       `json:"-"` // players still to draw this round
+	choices        []string       `json:"-"` // candidates offered to the drawer
+	revealed       map[int]bool   `json:"-"` // rune indexes of word shown in WordMask
+	hintAt         []int64        `json:"-"` // unix times of the hints still to come
+	turnPoints     map[string]int `json:"-"` // points earned this turn by player
+	aliases        []string       `json:"-"` // other accepted answers for word
+}
+
+// sent to the drawer only
+type WordChoices struct {
+	Words    []string `json:"words"`
+	Deadline int64    `json:"deadline"`
+}
+
+type WordChosen struct {
+	Word string `json:"word"`
+}
+
+// sent to the host after custom words were set
+type CustomWordsResult struct {
+	Accepted int      `json:"accepted"`
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+type TurnScore struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Points   int    `json:"points"` // earned this turn
+	Total    int    `json:"total"`
+}
+
+type TurnEnd struct {
+	Turn     int         `json:"turn"` // index for /room/:id/turns/:turn/thumbnail.png
+	Word     string      `json:"word"`
+	DrawerID string      `json:"drawerId"`
+	Reason   string      `json:"reason"`
+	Scores   []TurnScore `json:"scores"`
+}
+
+type Standing struct {
+	Rank     int    `json:"rank"`
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Points   int    `json:"points"`
+}
+
+type GameEnd struct {
+	Ranking []Standing `json:"ranking"`
+	Winners []string   `json:"winners"`
+	// when the room goes back to the lobby
+	LobbyAt int64 `json:"lobbyAt"`
+	// downloads for every turn of the game
+	Replays []TurnReplay `json:"replays"`
+}
+
+const (
+	ChatChannelAll     = "all"
+	ChatChannelGuessed = "guessed" // drawer and players who found the word
+)
+
+type ChatSender struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+}
+
+type ChatMsg struct {
+	Sender  ChatSender `json:"sender"`
+	Message string     `json:"message"`
+	Channel string     `json:"channel"`
+}
+
+type CorrectGuess struct {
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Message    string `json:"message"`
+}
+
+// EditDistance and Message are only filled in for the guesser themselves.
+type CloseGuess struct {
+	PlayerID     string `json:"playerId"`
+	PlayerName   string `json:"playerName"`
+	EditDistance int    `json:"editDistance"`
+	Message      string `json:"message"`
+}
+
+type UserLeft struct {
+	UserID string `json:"userId"`
+}
+
+type ErrorMsg struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type HostChanged struct {
+	HostID string `json:"hostId"`
+}
+
+type Hint struct {
+	WordMask string `json:"wordMask"`
+}
+
+type TimerTick struct {
+	Phase     string `json:"phase"`
+	EndsAt    int64  `json:"endsAt"`
+	Remaining int64  `json:"remaining"`
 }
 
 type RoomSnapshot struct {
-	RoomID  string          `json:"roomId"`
-	Players []PlayerSummary `json:"players"`
-	Game    *GameState      `json:"game,omitempty"`
-	Strokes []Stroke        `json:"strokes,omitempty"`
-	HostID  string          `json:"hostId,omitempty"`
+	RoomID   string          `json:"roomId"`
+	Players  []PlayerSummary `json:"players"`
+	Game     *GameState      `json:"game,omitempty"`
+	Strokes  []Stroke        `json:"strokes,omitempty"`
+	HostID   string          `json:"hostId,omitempty"`
+	Settings *RoomSettings   `json:"settings,omitempty"`
 }
 
 // non-ephemeral player sessions (for rejoins)
@@ -44,6 +195,9 @@ type PlayerSession struct {
 	Points  int
 	Online  bool
 	Guessed bool
+
+	joinedAt     time.Time
+	offlineSince time.Time
 }
 
 type WSMessage struct {
@@ -58,12 +212,48 @@ type Point struct {
 	Type  string  `json:"type,omitempty"`
 }
 
+// DrawPoint is one live pointer sample streamed while the drawer draws.
+type DrawPoint struct {
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	Type       string  `json:"type"` // start, move or end
+	PointColor string  `json:"pointColor"`
+	PointSize  float64 `json:"pointSize"`
+}
+
+// Stroke is one committed line on the canvas. ID and Seq are assigned by
+// the server; Seq orders strokes on every client's canvas.
 type Stroke struct {
+	ID          string  `json:"id"`
+	Seq         int64   `json:"seq"`
 	StrokeColor string  `json:"strokeColor"`
 	StrokeWidth int8    `json:"strokeWidth"`
 	Paths       []Point `json:"paths"`
 }
 
+// StrokeBegin announces a live stroke; its points follow in StrokePoints.
+type StrokeBegin struct {
+	StrokeID    string `json:"strokeId"`
+	PlayerID    string `json:"playerId"`
+	StrokeColor string `json:"strokeColor"`
+	StrokeWidth int8   `json:"strokeWidth"`
+}
+
+type StrokePoints struct {
+	StrokeID string  `json:"strokeId"`
+	Points   []Point `json:"points"`
+}
+
+// StrokeRemoved is sent with TypeUndo and TypeStrokeCancel.
+type StrokeRemoved struct {
+	StrokeID string `json:"strokeId"`
+}
+
+type PlayerRenamed struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+}
+
 type PlayerSummary struct {
 	ID     string `json:"playerId"`
 	Points int    `json:"points"`
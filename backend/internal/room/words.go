@@ -0,0 +1,99 @@
+package room
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sakshamg567/doodlz/backend/pkg/utils"
+)
+
+// WordMix weighs how often each difficulty tier is drawn from. A zero mix
+// means any word from the bank.
+type WordMix struct {
+	Easy   int `json:"easy"`
+	Medium int `json:"medium"`
+	Hard   int `json:"hard"`
+}
+
+var defaultWordMix = WordMix{Easy: 1, Medium: 1, Hard: 1}
+
+func (m WordMix) valid() bool {
+	return m.Easy >= 0 && m.Medium >= 0 && m.Hard >= 0
+}
+
+// pick returns a difficulty tier for utils.GetRandomWord according to the weights.
+func (m WordMix) pick() int {
+	total := m.Easy + m.Medium + m.Hard
+	if total <= 0 {
+		return utils.DifficultyAny
+	}
+
+	n := rand.Intn(total)
+	switch {
+	case n < m.Easy:
+		return utils.DifficultyEasy
+	case n < m.Easy+m.Medium:
+		return utils.DifficultyMedium
+	default:
+		return utils.DifficultyHard
+	}
+}
+
+const (
+	customWordMinLen = 2
+	customWordMaxLen = 32
+	maxCustomWords   = 500
+)
+
+// newCustomWords cleans up the host's list. Words that fail validation are
+// returned separately so the host can be told about them.
+func newCustomWords(words []string) (accepted []string, rejected []string) {
+	seen := make(map[string]bool)
+	for _, w := range words {
+		w = strings.Join(strings.Fields(w), " ")
+		if !validCustomWord(w) || len(accepted) >= maxCustomWords {
+			rejected = append(rejected, w)
+			continue
+		}
+
+		key := strings.ToLower(w)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		accepted = append(accepted, w)
+	}
+	return accepted, rejected
+}
+
+// validCustomWord allows letters, single spaces, hyphens and apostrophes.
+func validCustomWord(w string) bool {
+	n := utf8.RuneCountInString(w)
+	if n < customWordMinLen || n > customWordMaxLen {
+		return false
+	}
+
+	letters := 0
+	for _, c := range w {
+		switch {
+		case unicode.IsLetter(c):
+			letters++
+		case c == ' ' || c == '-' || c == '\'':
+		default:
+			return false
+		}
+	}
+	return letters > 0
+}
+
+// pickWordLocked draws one word for the room, honoring custom words, the
+// selected packs and the difficulty mix.
+func (r *Room) pickWordLocked() (string, error) {
+	st := r.Settings
+	if len(r.CustomWords) > 0 && (st.CustomOnly || rand.Float64() < st.CustomRatio) {
+		return r.CustomWords[rand.Intn(len(r.CustomWords))], nil
+	}
+	return utils.GetRandomWordFrom(st.packs(), st.WordMix.pick())
+}
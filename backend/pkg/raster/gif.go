@@ -0,0 +1,59 @@
+package raster
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"time"
+)
+
+// Frame is the canvas at one point of a replay, shown for Delay.
+type Frame struct {
+	Strokes []Stroke
+	Delay   time.Duration
+}
+
+// GIF renders frames into a looping animated GIF.
+func GIF(frames []Frame, w, h int) ([]byte, error) {
+	anim := &gif.GIF{}
+	q := quantizer{cache: make(map[color.RGBA]uint8)}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for _, f := range frames {
+		Draw(img, f.Strokes)
+		anim.Image = append(anim.Image, q.paletted(img))
+		// GIF delays are in hundredths of a second, browsers clamp below 2
+		anim.Delay = append(anim.Delay, max(int(f.Delay/(10*time.Millisecond)), 2))
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// quantizer maps colors onto the Plan 9 palette, remembering every lookup
+// since a drawing only uses a handful of colors and their blended edges.
+type quantizer struct {
+	cache map[color.RGBA]uint8
+}
+
+func (q quantizer) paletted(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+	out := image.NewPaletted(b, palette.Plan9)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			i, ok := q.cache[c]
+			if !ok {
+				i = uint8(out.Palette.Index(c))
+				q.cache[c] = i
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+	return out
+}
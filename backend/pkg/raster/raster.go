@@ -0,0 +1,160 @@
+// Package raster draws stroke logs into images without a browser.
+package raster
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// RefWidth is the canvas width stroke widths are measured against. Wider or
+// narrower images scale the widths along.
+const RefWidth = 800
+
+// Background is what the canvas is filled with before the first stroke and
+// after every clear.
+var Background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+// Point is a position in normalized canvas space, (0,0) top left and (1,1)
+// bottom right.
+type Point struct {
+	X, Y float64
+}
+
+// Stroke is one line to draw, or a clear of the whole canvas when Clear is set.
+type Stroke struct {
+	Color  string
+	Width  float64
+	Points []Point
+	Clear  bool
+}
+
+// Render replays strokes into a new w by h image.
+func Render(strokes []Stroke, w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	Draw(img, strokes)
+	return img
+}
+
+// Draw replays strokes onto img, starting from a blank canvas.
+func Draw(img *image.RGBA, strokes []Stroke) {
+	fill(img, Background)
+	scale := float64(img.Bounds().Dx()) / RefWidth
+
+	for _, s := range strokes {
+		if s.Clear {
+			fill(img, Background)
+			continue
+		}
+		c, ok := ParseColor(s.Color)
+		if !ok {
+			c = color.RGBA{0, 0, 0, 0xff}
+		}
+		r := math.Max(s.Width*scale/2, 0.5)
+
+		if len(s.Points) == 1 {
+			segment(img, s.Points[0], s.Points[0], r, c)
+		}
+		for i := 1; i < len(s.Points); i++ {
+			segment(img, s.Points[i-1], s.Points[i], r, c)
+		}
+	}
+}
+
+// PNG renders strokes and encodes the result.
+func PNG(strokes []Stroke, w, h int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, Render(strokes, w, h)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseColor reads "#rgb" or "#rrggbb".
+func ParseColor(s string) (color.RGBA, bool) {
+	if len(s) == 4 && s[0] == '#' {
+		s = string([]byte{'#', s[1], s[1], s[2], s[2], s[3], s[3]})
+	}
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, true
+}
+
+func fill(img *image.RGBA, c color.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// segment paints a line from a to b with round ends, r pixels either side
+// of it. Edge pixels are blended by how much of them the line covers.
+//
+// The line is walked in pieces about as long as the brush is wide and only
+// the box around each piece is scanned, so the cost grows with the length
+// of the line rather than with the area of its bounding box. Each pixel is
+// painted by the one piece its closest point on a-b falls in.
+func segment(img *image.RGBA, a, b Point, r float64, c color.RGBA) {
+	bounds := img.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	ax, ay := a.X*w, a.Y*h
+	bx, by := b.X*w, b.Y*h
+
+	dx, dy := bx-ax, by-ay
+	lenSq := dx*dx + dy*dy
+	pieces := max(1, int(math.Ceil(math.Sqrt(lenSq)/(2*r+2))))
+
+	for i := 0; i < pieces; i++ {
+		t0, t1 := float64(i)/float64(pieces), float64(i+1)/float64(pieces)
+		x0, y0 := ax+t0*dx, ay+t0*dy
+		x1, y1 := ax+t1*dx, ay+t1*dy
+
+		minX := max(int(math.Floor(math.Min(x0, x1)-r-1)), bounds.Min.X)
+		maxX := min(int(math.Ceil(math.Max(x0, x1)+r+1)), bounds.Max.X)
+		minY := max(int(math.Floor(math.Min(y0, y1)-r-1)), bounds.Min.Y)
+		maxY := min(int(math.Ceil(math.Max(y0, y1)+r+1)), bounds.Max.Y)
+
+		for y := minY; y < maxY; y++ {
+			for x := minX; x < maxX; x++ {
+				px, py := float64(x)+0.5, float64(y)+0.5
+
+				// closest point on a-b to the pixel centre
+				t := 0.0
+				if lenSq > 0 {
+					t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/lenSq))
+				}
+				if min(int(t*float64(pieces)), pieces-1) != i {
+					continue
+				}
+				d := math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+
+				cover := math.Min(1, r+0.5-d)
+				if cover <= 0 {
+					continue
+				}
+				if cover == 1 {
+					img.SetRGBA(x, y, c)
+					continue
+				}
+				img.SetRGBA(x, y, blend(img.RGBAAt(x, y), c, cover))
+			}
+		}
+	}
+}
+
+func blend(dst, src color.RGBA, a float64) color.RGBA {
+	mix := func(d, s uint8) uint8 {
+		return uint8(math.Round(float64(s)*a + float64(d)*(1-a)))
+	}
+	return color.RGBA{mix(dst.R, src.R), mix(dst.G, src.G), mix(dst.B, src.B), 0xff}
+}
@@ -0,0 +1,72 @@
+package raster
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+		ok   bool
+	}{
+		{"#ff8000", color.RGBA{0xff, 0x80, 0x00, 0xff}, true},
+		{"#f80", color.RGBA{0xff, 0x88, 0x00, 0xff}, true},
+		{"ff8000", color.RGBA{}, false},
+		{"#ff80", color.RGBA{}, false},
+		{"#zzzzzz", color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseColor(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	black := color.RGBA{0, 0, 0, 0xff}
+	// 80 wide at RefWidth is 10 pixels wide on a 100 pixel canvas
+	across := func(c string, y float64) Stroke {
+		return Stroke{Color: c, Width: 80, Points: []Point{{0.1, y}, {0.9, y}}}
+	}
+
+	tests := []struct {
+		name    string
+		strokes []Stroke
+		x, y    int
+		want    color.RGBA
+	}{
+		{"blank", nil, 50, 50, Background},
+		{"on the line", []Stroke{across("#ff0000", 0.5)}, 50, 50, red},
+		{"inside the width", []Stroke{across("#ff0000", 0.5)}, 50, 46, red},
+		{"beside the line", []Stroke{across("#ff0000", 0.5)}, 50, 40, Background},
+		{"round cap", []Stroke{across("#ff0000", 0.5)}, 7, 50, red},
+		{"past the cap", []Stroke{across("#ff0000", 0.5)}, 2, 50, Background},
+		{"single point", []Stroke{{Color: "#0000ff", Width: 80, Points: []Point{{0.5, 0.5}}}}, 52, 52, blue},
+		{"later stroke on top", []Stroke{across("#ff0000", 0.5), across("#0000ff", 0.5)}, 50, 50, blue},
+		{"bad color is black", []Stroke{across("nope", 0.5)}, 50, 50, black},
+		{"clear wipes", []Stroke{across("#ff0000", 0.5), {Clear: true}}, 50, 50, Background},
+		{"drawn after clear", []Stroke{across("#ff0000", 0.5), {Clear: true}, across("#0000ff", 0.2)}, 50, 20, blue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := Render(tt.strokes, 100, 100)
+			if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+				t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBlendsEdges(t *testing.T) {
+	img := Render([]Stroke{{Color: "#000000", Width: 80, Points: []Point{{0.1, 0.503}, {0.9, 0.503}}}}, 100, 100)
+	// the line runs from 45.3 to 55.3, so row 45 is 70% covered
+	got := img.RGBAAt(50, 45)
+	if got.R == 0 || got.R == 0xff {
+		t.Errorf("edge pixel = %v, want a blend of black and white", got)
+	}
+}
@@ -3,51 +3,194 @@ package utils
 import (
 	"errors"
 	"math/rand"
-	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 const wordBankPath = "skribbl-word-bank/skribbl_words_drawability_en.txt"
 
+// Difficulty tiers accepted by GetRandomWord. DifficultyAny ignores tiers.
+const (
+	DifficultyAny = iota
+	DifficultyEasy
+	DifficultyMedium
+	DifficultyHard
+)
+
 var (
 	loadOnce sync.Once
-	wordList []string
+	packs    atomic.Pointer[map[string]*WordPack]
 	loadErr  error
+
+	// wordBanks are the sources loaded on first use, see UseWordBanks.
+	wordBanks = []WordBank{
+		EmbeddedWordBank{},
+		FileWordBank{Path: wordBankPath, Pack: "en/skribbl", Optional: true},
+	}
 )
 
-func loadWords() error {
-	data, err := os.ReadFile(wordBankPath)
+// UseWordBanks replaces the default word sources. It must be called before
+// the first word is drawn or ReloadWords is called.
+func UseWordBanks(banks ...WordBank) {
+	wordBanks = banks
+}
+
+type scoredWord struct {
+	word  string
+	score float64
+}
+
+// parseLine splits "word<sep>score" where sep is a tab, comma, semicolon or
+// pipe. Lines without a numeric score are returned whole with ok=false.
+func parseLine(l string) (w scoredWord, ok bool) {
+	i := strings.LastIndexAny(l, "\t,;|")
+	if i < 0 {
+		return scoredWord{word: l}, false
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(l[i+1:]), 64)
+	if err != nil {
+		return scoredWord{word: l}, false
+	}
+	return scoredWord{word: strings.TrimSpace(l[:i]), score: score}, true
+}
+
+// bucketWords sorts scored words by drawability, most drawable first, and
+// cuts them into thirds. Unscored words are treated as medium.
+func bucketWords(scored []scoredWord, unscored []string) map[int][]string {
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	b := map[int][]string{
+		DifficultyEasy:   {},
+		DifficultyMedium: append([]string{}, unscored...),
+		DifficultyHard:   {},
+	}
+	for i, sw := range scored {
+		switch {
+		case i < len(scored)/3:
+			b[DifficultyEasy] = append(b[DifficultyEasy], sw.word)
+		case i < 2*len(scored)/3:
+			b[DifficultyMedium] = append(b[DifficultyMedium], sw.word)
+		default:
+			b[DifficultyHard] = append(b[DifficultyHard], sw.word)
+		}
+	}
+	return b
+}
+
+// ReloadWords reads every word bank again and swaps the result in at once.
+// Words already handed out are unaffected. If loading fails the previous
+// packs stay in use and the error is returned.
+func ReloadWords() error {
+	loaded, err := loadBanks(wordBanks)
 	if err != nil {
 		return err
 	}
-	lines := strings.Split(string(data), "\n")
-	tmp := make([]string, 0, len(lines))
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l != "" {
-			tmp = append(tmp, l)
+	packs.Store(&loaded)
+	return nil
+}
+
+func getPacks() (map[string]*WordPack, error) {
+	loadOnce.Do(func() {
+		if packs.Load() == nil {
+			loadErr = ReloadWords()
 		}
+	})
+	if p := packs.Load(); p != nil {
+		return *p, nil
 	}
-	if len(tmp) == 0 {
-		return errors.New("word bank empty after parsing")
+	return nil, loadErr
+}
+
+// PackNames lists the loaded word packs, e.g. "en/animals".
+func PackNames() ([]string, error) {
+	all, err := getPacks()
+	if err != nil {
+		return nil, err
 	}
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
 
-	wordList = tmp
+// CheckPacks reports an error unless every selector matches a loaded pack.
+func CheckPacks(selectors []string) error {
+	all, err := getPacks()
+	if err != nil {
+		return err
+	}
+	for _, sel := range selectors {
+		if _, err := matchPacks(all, []string{sel}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Aliases returns the alternative answers the word bank lists for word.
+func Aliases(word string) []string {
+	all, err := getPacks()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, p := range all {
+		out = append(out, p.Aliases[word]...)
+	}
+	return out
+}
+
+// GetRandomWord returns a word of the requested difficulty from any pack.
 func GetRandomWord(difficulty int) (string, error) {
-	loadOnce.Do(func() {
-		loadErr = loadWords()
-	})
-	if loadErr != nil {
-		return "", loadErr
+	return GetRandomWordFrom(nil, difficulty)
+}
+
+// GetRandomWordFrom returns a word of the requested difficulty from the packs
+// matching selectors ("en/animals", or "en" for every English pack). When no
+// selected pack has words in that tier, the whole selection is used.
+func GetRandomWordFrom(selectors []string, difficulty int) (string, error) {
+	all, err := getPacks()
+	if err != nil {
+		return "", err
 	}
-	if len(wordList) == 0 {
-		return "", errors.New("no words in wordlist")
+	selected, err := matchPacks(all, selectors)
+	if err != nil {
+		return "", err
 	}
 
-	idx := rand.Intn(len(wordList))
-	return wordList[idx], nil
+	if word, ok := pickFrom(selected, func(p *WordPack) []string { return p.tiers[difficulty] }); ok {
+		return word, nil
+	}
+	if word, ok := pickFrom(selected, func(p *WordPack) []string { return p.Words }); ok {
+		return word, nil
+	}
+	return "", errors.New("no words in wordlist")
+}
+
+// pickFrom picks uniformly across the lists returned for each pack.
+func pickFrom(selected []*WordPack, list func(*WordPack) []string) (string, bool) {
+	total := 0
+	for _, p := range selected {
+		total += len(list(p))
+	}
+	if total == 0 {
+		return "", false
+	}
+
+	idx := rand.Intn(total)
+	for _, p := range selected {
+		l := list(p)
+		if idx < len(l) {
+			return l[idx], true
+		}
+		idx -= len(l)
+	}
+	return "", false
 }
@@ -0,0 +1,201 @@
+package utils
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed words
+var embeddedWords embed.FS
+
+// WordPack is a list of words for one language and category, e.g. "en/animals".
+// A line may list accepted alternatives after the word: "tv|television,0.7".
+type WordPack struct {
+	Name    string
+	Words   []string
+	Aliases map[string][]string
+	tiers   map[int][]string
+}
+
+// WordBank is a source of word packs.
+type WordBank interface {
+	Load() ([]*WordPack, error)
+}
+
+// FileWordBank reads a single word file into the pack named Pack.
+// Optional banks that do not exist on disk load as empty instead of failing.
+type FileWordBank struct {
+	Path     string
+	Pack     string
+	Optional bool
+}
+
+func (b FileWordBank) Load() ([]*WordPack, error) {
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		if b.Optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []*WordPack{parsePack(b.Pack, data)}, nil
+}
+
+// DirWordBank reads every <lang>/<category>.txt below Root.
+type DirWordBank struct {
+	Root string
+}
+
+func (b DirWordBank) Load() ([]*WordPack, error) {
+	return loadPacksFS(os.DirFS(b.Root))
+}
+
+// EmbeddedWordBank serves the packs compiled into the binary.
+type EmbeddedWordBank struct{}
+
+func (EmbeddedWordBank) Load() ([]*WordPack, error) {
+	sub, err := fs.Sub(embeddedWords, "words")
+	if err != nil {
+		return nil, err
+	}
+	return loadPacksFS(sub)
+}
+
+func loadPacksFS(fsys fs.FS) ([]*WordPack, error) {
+	var packs []*WordPack
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".txt" {
+			return nil
+		}
+
+		lang, file := path.Split(p)
+		lang = strings.Trim(lang, "/")
+		if lang == "" || strings.Contains(lang, "/") {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		packs = append(packs, parsePack(lang+"/"+strings.TrimSuffix(file, ".txt"), data))
+		return nil
+	})
+	return packs, err
+}
+
+func parsePack(name string, data []byte) *WordPack {
+	lines := strings.Split(string(data), "\n")
+	words := make([]string, 0, len(lines))
+	aliases := make(map[string][]string)
+	scored := make([]scoredWord, 0, len(lines))
+	unscored := make([]string, 0)
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		sw, ok := parseLine(l)
+		names := strings.Split(sw.word, "|")
+		sw.word = strings.TrimSpace(names[0])
+		if sw.word == "" {
+			continue
+		}
+		for _, alias := range names[1:] {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases[sw.word] = append(aliases[sw.word], alias)
+			}
+		}
+		if ok {
+			scored = append(scored, sw)
+		} else {
+			unscored = append(unscored, sw.word)
+		}
+		words = append(words, sw.word)
+	}
+
+	return &WordPack{
+		Name:    name,
+		Words:   words,
+		Aliases: aliases,
+		tiers:   bucketWords(scored, unscored),
+	}
+}
+
+// loadBanks collects packs from every bank. Packs with the same name coming
+// from different banks are merged.
+func loadBanks(banks []WordBank) (map[string]*WordPack, error) {
+	merged := make(map[string]*WordPack)
+	for _, b := range banks {
+		packs, err := b.Load()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range packs {
+			if len(p.Words) == 0 {
+				continue
+			}
+			if prev, ok := merged[p.Name]; ok {
+				p = mergePacks(prev, p)
+			}
+			merged[p.Name] = p
+		}
+	}
+	if len(merged) == 0 {
+		return nil, errors.New("word bank empty after parsing")
+	}
+	return merged, nil
+}
+
+func mergePacks(a, b *WordPack) *WordPack {
+	out := &WordPack{
+		Name:    a.Name,
+		Words:   append(append([]string{}, a.Words...), b.Words...),
+		Aliases: make(map[string][]string),
+		tiers:   make(map[int][]string),
+	}
+	for _, src := range []*WordPack{a, b} {
+		for w, al := range src.Aliases {
+			out.Aliases[w] = append(out.Aliases[w], al...)
+		}
+	}
+	for _, d := range []int{DifficultyEasy, DifficultyMedium, DifficultyHard} {
+		out.tiers[d] = append(append([]string{}, a.tiers[d]...), b.tiers[d]...)
+	}
+	return out
+}
+
+// matchPacks resolves pack selectors against the loaded packs. A selector is
+// either a full "lang/category" name or a bare language, which matches every
+// category in it. No selectors means every pack.
+func matchPacks(packs map[string]*WordPack, selectors []string) ([]*WordPack, error) {
+	var out []*WordPack
+	for name, p := range packs {
+		if len(selectors) == 0 {
+			out = append(out, p)
+			continue
+		}
+		for _, sel := range selectors {
+			if name == sel || strings.HasPrefix(name, sel+"/") {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no word packs match %v", selectors)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want scoredWord
+		ok   bool
+	}{
+		{"cat,0.95", scoredWord{"cat", 0.95}, true},
+		{"ice cream\t0.5", scoredWord{"ice cream", 0.5}, true},
+		{"tv|television;0.7", scoredWord{"tv|television", 0.7}, true},
+		{"dog", scoredWord{word: "dog"}, false},
+		{"dog,many", scoredWord{word: "dog,many"}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLine(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseLine(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBucketWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		scored   []scoredWord
+		unscored []string
+		want     map[int][]string
+	}{
+		{
+			"thirds by score",
+			[]scoredWord{{"d", 0.4}, {"a", 0.9}, {"f", 0.1}, {"b", 0.8}, {"e", 0.3}, {"c", 0.6}},
+			nil,
+			map[int][]string{DifficultyEasy: {"a", "b"}, DifficultyMedium: {"c", "d"}, DifficultyHard: {"e", "f"}},
+		},
+		{
+			"unscored are medium",
+			[]scoredWord{{"a", 0.9}, {"b", 0.5}, {"c", 0.1}},
+			[]string{"x"},
+			map[int][]string{DifficultyEasy: {"a"}, DifficultyMedium: {"x", "b"}, DifficultyHard: {"c"}},
+		},
+		{
+			"ties keep file order",
+			[]scoredWord{{"a", 0.5}, {"b", 0.5}, {"c", 0.5}},
+			nil,
+			map[int][]string{DifficultyEasy: {"a"}, DifficultyMedium: {"b"}, DifficultyHard: {"c"}},
+		},
+		{
+			"nothing scored",
+			nil,
+			[]string{"x", "y"},
+			map[int][]string{DifficultyEasy: {}, DifficultyMedium: {"x", "y"}, DifficultyHard: {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bucketWords(tt.scored, tt.unscored); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bucketWords = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePack(t *testing.T) {
+	data := []byte(`# animals
+cat|kitty,0.95
+rabbit | bunny | hare ,0.86
+
+dog
+tv|,0.7
+|orphan,0.5
+`)
+	p := parsePack("en/test", data)
+
+	if want := []string{"cat", "rabbit", "dog", "tv"}; !reflect.DeepEqual(p.Words, want) {
+		t.Errorf("Words = %q, want %q", p.Words, want)
+	}
+	wantAliases := map[string][]string{
+		"cat":    {"kitty"},
+		"rabbit": {"bunny", "hare"},
+	}
+	if !reflect.DeepEqual(p.Aliases, wantAliases) {
+		t.Errorf("Aliases = %q, want %q", p.Aliases, wantAliases)
+	}
+	if got := p.tiers[DifficultyMedium]; !reflect.DeepEqual(got, []string{"dog", "rabbit"}) {
+		t.Errorf("medium tier = %q, want %q", got, []string{"dog", "rabbit"})
+	}
+}
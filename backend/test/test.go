@@ -131,6 +131,18 @@ func connectAndSpam(roomId, playerId string) {
 
 	fmt.Printf("%s joined\n", playerId)
 
+	// every other bot says hello first, the rest speak the legacy protocol
+	if rand.Intn(2) == 0 {
+		hello := WSMessage{
+			Type: "hello",
+			Data: json.RawMessage(fmt.Sprintf(`{"version":1,"capabilities":["stroke_streaming"],"name":"%s"}`, playerId)),
+		}
+		if err := conn.WriteJSON(hello); err != nil {
+			log.Printf("Hello error for %s: %v", playerId, err)
+			return
+		}
+	}
+
 	// Create different message types to test
 	messages := []WSMessage{
 		{
@@ -1,27 +1,41 @@
 import React, { useCallback, useEffect, useRef, useState, useMemo } from "react"
-import { type WSMessage, type Stroke, type Point, type Player, type UiMessage } from "./types/types"
-import { sendPoint, drawPoint, pointerPos } from "./core"
+import { type WSMessage, type Stroke, type Player, type UiMessage } from "./types/types"
+import { drawPoint, pointerPos } from "./core"
 import { getOrCreateGuestId } from "./core/lib/guesId"
 import normalizeInbound from "./core/lib/normalizeUiMsg";
 import { useIsMobile } from "./hooks/useIsMobile";
 import { MobileLayout } from "./components/MobileLayout";
 import { NormalLayout } from "./components/NormalLayout";
+import { WordPicker } from "./components/WordPicker";
+import type { HeaderProps } from "./components/Header";
 
 const Game = ({ roomId }: { roomId: string }) => {
    const canvasRef = useRef<HTMLCanvasElement | null>(null)
    const ctxRef = useRef<CanvasRenderingContext2D | null>(null)
    const socketRef = useRef<WebSocket | null>(null)
    const drawingRef = useRef(false)
+   // stroke another player is drawing right now, streamed in batches
+   const liveRef = useRef<{ id: string, own: boolean, color: string, width: number, last: { x: number, y: number } | null } | null>(null)
    const listRef = useRef(null);
 
    const [connectedUsers, setConnectedUsers] = useState<Player[] | []>([])
    const [allStrokes, setAllStrokes] = useState<Stroke[]>([])
-   const [currentStroke, setCurrentStroke] = useState<Point[]>([])
    const [messages, setMessages] = useState<UiMessage[]>([]);
    const [input, setInput] = useState("")
    // const [points, setPoints] = useState(0);
 
-   const [isHost, setIsHost] = useState<boolean>(false)
+   // only the current drawer may draw, the server drops anyone else's strokes
+   const [drawerId, setDrawerId] = useState("")
+   const [phase, setPhase] = useState("lobby")
+   const [hostId, setHostId] = useState("")
+   const [round, setRound] = useState(0)
+   const [maxRounds, setMaxRounds] = useState(0)
+   const [endsAt, setEndsAt] = useState(0)
+   // what guessers see of the word, and the word itself once we know it
+   const [wordMask, setWordMask] = useState("")
+   const [word, setWord] = useState("")
+   // offered to us while we are the drawer choosing a word
+   const [wordChoices, setWordChoices] = useState<string[]>([])
    const isMobile = useIsMobile()
 
    const [strokeColor, setStrokeColor] = useState("#000000");
@@ -38,6 +52,7 @@ const Game = ({ roomId }: { roomId: string }) => {
 
 
    const id = getOrCreateGuestId();
+   const isDrawer = phase === "drawing" && drawerId === id;
 
    useEffect(() => {
       const canvas = canvasRef.current
@@ -58,6 +73,12 @@ const Game = ({ roomId }: { roomId: string }) => {
       }
 
       socketRef.current = new WebSocket(`ws://localhost:3000/ws/${roomId}/${id}`)
+      socketRef.current.onopen = () => {
+         socketRef.current?.send(JSON.stringify({
+            type: "hello",
+            data: { version: 1, capabilities: ["stroke_streaming", "compression"], name: localStorage.getItem("doodlz_name") ?? "" },
+         }))
+      }
       socketRef.current.onmessage = handleSocketMessage
 
       return () => socketRef.current?.close()
@@ -103,7 +124,27 @@ const Game = ({ roomId }: { roomId: string }) => {
             drawPoint(ctxRef, raw.data);
             return;
          case 'stroke':
-            setAllStrokes(prev => [...prev, raw.data as Stroke]);
+            if (liveRef.current?.id === raw.data.id) liveRef.current = null;
+            setAllStrokes(prev => prev.some(s => s.id === raw.data.id) ? prev : [...prev, raw.data as Stroke]);
+            return;
+         case 'stroke_begin':
+            liveRef.current = {
+               id: raw.data.strokeId,
+               own: raw.data.playerId === id,
+               color: raw.data.strokeColor,
+               width: raw.data.strokeWidth,
+               last: null,
+            };
+            return;
+         case 'stroke_points':
+            drawLivePoints(raw.data.strokeId, raw.data.points);
+            return;
+         case 'stroke_cancel':
+            if (liveRef.current?.id === raw.data.strokeId) liveRef.current = null;
+            setAllStrokes(prev => {
+               replayAllStrokes(prev);
+               return prev;
+            });
             return;
          case 'clear':
             clearCanvas();
@@ -111,8 +152,14 @@ const Game = ({ roomId }: { roomId: string }) => {
             return;
          case 'undo':
             setAllStrokes(prev => {
-               if (!prev.length) return prev;
-               const upd = prev.slice(0, -1);
+               const upd = prev.filter(s => s.id !== raw.data.strokeId);
+               replayAllStrokes(upd);
+               return upd;
+            });
+            return;
+         case 'redo':
+            setAllStrokes(prev => {
+               const upd = [...prev, raw.data as Stroke].sort((a, b) => (a.seq ?? 0) - (b.seq ?? 0));
                replayAllStrokes(upd);
                return upd;
             });
@@ -126,7 +173,31 @@ const Game = ({ roomId }: { roomId: string }) => {
          case 'game_state':
             replayAllStrokesWithDelay(raw.data.strokes || []);
             setAllStrokes(raw.data.strokes || []);
-            if (raw.data.hostId) setIsHost(raw.data.hostId === id);
+            setHostId(raw.data.hostId ?? "");
+            if (raw.data.game) applyGameState(raw.data.game);
+            return;
+         case 'phase_change':
+            // word_choices and word_chosen for the new phase follow this message
+            applyGameState(raw.data);
+            setWordChoices([]);
+            setWord("");
+            return;
+         case 'host_changed':
+            setHostId(raw.data.hostId);
+            return;
+         case 'timer':
+            setEndsAt(raw.data.endsAt);
+            return;
+         case 'word_choices':
+            setWordChoices(raw.data.words ?? []);
+            setEndsAt(raw.data.deadline);
+            return;
+         case 'word_chosen':
+            setWordChoices([]);
+            setWord(raw.data.word);
+            return;
+         case 'hint':
+            setWordMask(raw.data.wordMask);
             return;
          default: {
             const ui = normalizeInbound(raw);
@@ -134,6 +205,38 @@ const Game = ({ roomId }: { roomId: string }) => {
          }
       }
    }
+   const applyGameState = (g: { phase: string, drawerId: string, round: number, maxRounds: number, wordMask: string, endsAt: number }) => {
+      setPhase(g.phase);
+      setDrawerId(g.drawerId);
+      setRound(g.round);
+      setMaxRounds(g.maxRounds);
+      setWordMask(g.wordMask);
+      setEndsAt(g.endsAt);
+   }
+
+   // continue the live stroke with a batch of points; the drawer already has them on screen
+   const drawLivePoints = (strokeId: string, points: { x: number, y: number }[]) => {
+      const ctx = ctxRef.current
+      const live = liveRef.current
+      if (!ctx || !live || live.id !== strokeId || live.own) return
+      ctx.save();
+      ctx.strokeStyle = live.color;
+      ctx.lineWidth = live.width;
+      ctx.beginPath();
+      let last = live.last ?? points[0];
+      if (!last) return ctx.restore();
+      ctx.moveTo(last.x * ctx.canvas.width, last.y * ctx.canvas.height);
+      points.forEach(pt => {
+         ctx.lineTo(pt.x * ctx.canvas.width, pt.y * ctx.canvas.height);
+         last = pt;
+      });
+      ctx.stroke();
+      ctx.restore();
+      live.last = last;
+   }
+
+   const sendMsg = (msg: WSMessage) => socketRef.current?.send(JSON.stringify(msg))
+
    const replayAllStrokes = (strokes: Stroke[]) => {
       const ctx = ctxRef.current
       if (!ctx) return
@@ -143,8 +246,8 @@ const Game = ({ roomId }: { roomId: string }) => {
          ctx.strokeStyle = stroke.strokeColor || "#000000";
          ctx.lineWidth = stroke.strokeWidth || 3;
          stroke.paths.forEach((pt, i) => {
-            if (i === 0) ctx.moveTo(pt.x, pt.y);
-            else ctx.lineTo(pt.x, pt.y);
+            if (i === 0) ctx.moveTo(pt.x * ctx.canvas.width, pt.y * ctx.canvas.height);
+            else ctx.lineTo(pt.x * ctx.canvas.width, pt.y * ctx.canvas.height);
          });
          ctx.stroke();
       });
@@ -168,9 +271,9 @@ const Game = ({ roomId }: { roomId: string }) => {
 
          if (p === 0) {
             ctx.beginPath();
-            ctx.moveTo(pt.x, pt.y);
+            ctx.moveTo(pt.x * ctx.canvas.width, pt.y * ctx.canvas.height);
          } else {
-            ctx.lineTo(pt.x, pt.y);
+            ctx.lineTo(pt.x * ctx.canvas.width, pt.y * ctx.canvas.height);
          }
          ctx.stroke();
 
@@ -186,64 +289,46 @@ const Game = ({ roomId }: { roomId: string }) => {
    }
 
    const handlePointerDown = (e: React.PointerEvent) => {
-      if (!isHost) return;
+      if (!isDrawer) return;
       e.preventDefault();
       const ctx = ctxRef.current;
       if (!ctx) return;
 
       canvasRef.current?.setPointerCapture(e.pointerId);
       drawingRef.current = true;
-      setCurrentStroke([]);
       const { x, y } = pointerPos(e, canvasRef);
       ctx.beginPath();
-      ctx.moveTo(x, y);
-      const startPoint: Point = { x, y, type: "start", pointColor: strokeColor, pointSize: strokeWidth };
-      setCurrentStroke(prev => [...prev, startPoint]);
-      sendPoint(socketRef, startPoint);
+      ctx.moveTo(x * ctx.canvas.width, y * ctx.canvas.height);
+      sendMsg({ type: "stroke_begin", data: { strokeColor, strokeWidth } });
+      sendMsg({ type: "stroke_points", data: { points: [{ x, y, type: "start" }] } });
    };
 
    const handlePointerMove = (e: React.PointerEvent) => {
-      if (!drawingRef.current || !isHost) return;
+      if (!drawingRef.current || !isDrawer) return;
       e.preventDefault();
       const ctx = ctxRef.current;
       if (!ctx) return;
       const { x, y } = pointerPos(e, canvasRef);
-      ctx.lineTo(x, y);
+      ctx.lineTo(x * ctx.canvas.width, y * ctx.canvas.height);
       ctx.stroke();
-      const movePoint: Point = { x, y, type: "move", pointColor: strokeColor, pointSize: strokeWidth };
-      setCurrentStroke(prev => [...prev, movePoint]);
-      sendPoint(socketRef, movePoint);
+      // the server batches these before passing them on
+      sendMsg({ type: "stroke_points", data: { points: [{ x, y, type: "move" }] } });
    };
 
    const finishStroke = useCallback(() => {
       if (!drawingRef.current) return;
       drawingRef.current = false;
-
-      const completedStroke: Stroke = {
-         strokeColor,
-         strokeWidth,
-         paths: currentStroke
-      }
-
-      // Send stroke message to backend
-      const strokeMsg: WSMessage = {
-         type: "stroke",
-         data: completedStroke
-      }
-      socketRef.current?.send(JSON.stringify(strokeMsg))
-      setAllStrokes(prev => [...prev, completedStroke])
-      setCurrentStroke([]);
-      const endPoint: Point = { x: 0, y: 0, type: "end", pointColor: strokeColor, pointSize: strokeWidth };
-      sendPoint(socketRef, endPoint);
-   }, [strokeColor, strokeWidth, setCurrentStroke, currentStroke])
+      // the server echoes the committed stroke back with its id, that is when it lands in allStrokes
+      sendMsg({ type: "stroke_end", data: {} });
+   }, [])
 
    const handlePointerUp = (e: React.PointerEvent) => {
-      if (!isHost) return;
+      if (!isDrawer) return;
       e.preventDefault();
       finishStroke();
    };
    const handlePointerLeave = (e: React.PointerEvent) => {
-      if (!isHost) return;
+      if (!isDrawer) return;
       if (drawingRef.current) finishStroke();
    }
 
@@ -258,8 +343,25 @@ const Game = ({ roomId }: { roomId: string }) => {
       setInput('');
    };
 
+   const startGame = () => sendMsg({ type: "start_game", data: {} })
+
+   const chooseWord = (w: string) => {
+      sendMsg({ type: "choose_word", data: { word: w } })
+      setWordChoices([])
+   }
+
+   const header: HeaderProps = {
+      phase,
+      round,
+      maxRounds,
+      endsAt,
+      word: word || wordMask,
+      isHost: hostId === id,
+      startGame,
+   }
+
    const undoLast = () => {
-      if (!isHost) return
+      if (!isDrawer) return
       // undo handled in backend, just send an undo request
       const msg: WSMessage = { type: "undo", data: {} }
       socketRef.current?.send(JSON.stringify(msg))
@@ -272,8 +374,11 @@ const Game = ({ roomId }: { roomId: string }) => {
          {/* Doodle PNG Overlay */}
          <div className="absolute opacity-25 inset-0 bg-[url('/doodlez.webp')] bg-no-repeat bg-center bg-cover pointer-events-none" />
 
+         {wordChoices.length > 0 && <WordPicker words={wordChoices} chooseWord={chooseWord} />}
+
          {isMobile ?
             <MobileLayout
+               header={header}
                canvasRef={canvasRef}
                handlePointerDown={handlePointerDown}
                handlePointerLeave={handlePointerLeave}
@@ -295,6 +400,7 @@ const Game = ({ roomId }: { roomId: string }) => {
             />
             : (
                <NormalLayout
+                  header={header}
                   canvasRef={canvasRef}
                   handlePointerDown={handlePointerDown}
                   handlePointerLeave={handlePointerLeave}
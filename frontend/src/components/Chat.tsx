@@ -81,6 +81,12 @@ export const Chat = ({
                            </span>
                         </div>
                      );
+                  case 'system':
+                     return (
+                        <div key={i} className={`p-1 text-blue-700 italic ${i % 2 ? 'bg-gray-200' : ''}`}>
+                           {m.message}
+                        </div>
+                     );
                }
             })}
          </div>
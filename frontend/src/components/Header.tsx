@@ -1,9 +1,51 @@
-export const Header = () => {
+import { useEffect, useState } from "react"
+
+export interface HeaderProps {
+   phase: string,
+   round: number,
+   maxRounds: number,
+   // unix seconds the current phase ends at, 0 when it has no deadline
+   endsAt: number,
+   // the word for the drawer and players who guessed it, the mask for everyone else
+   word: string,
+   isHost: boolean,
+   startGame: () => void,
+}
+
+const Clock = ({ endsAt }: { endsAt: number }) => {
+   const [now, setNow] = useState(() => Date.now() / 1000)
+
+   useEffect(() => {
+      const t = setInterval(() => setNow(Date.now() / 1000), 250)
+      return () => clearInterval(t)
+   }, [])
+
+   if (!endsAt) return <div className="w-12" />
+   return <div className="w-12 font-semibold tabular-nums">{Math.max(0, Math.ceil(endsAt - now))}</div>
+}
+
+const phaseText: Record<string, string> = {
+   lobby: "Waiting for the host to start",
+   choosing_word: "Choosing a word...",
+   round_end: "Round over",
+   game_end: "Game over",
+}
+
+export const Header = ({ phase, round, maxRounds, endsAt, word, isHost, startGame }: HeaderProps) => {
+   const canStart = isHost && (phase === "lobby" || phase === "game_end")
+
    return (
-      <div id="header" className="flex flex-row place-content-around bg-white min-h-14 flex-shrink-0">
-         <div>CLOCK</div>
-         <div>PLACEHOLDER</div>
-         <div>SETTINGS</div>
+      <div id="header" className="flex flex-row items-center place-content-around bg-white min-h-14 flex-shrink-0">
+         <Clock endsAt={endsAt} />
+         <div className="flex flex-col items-center">
+            {phase === "drawing" && word
+               ? <div className="font-mono text-lg tracking-[0.3em]">{word}</div>
+               : <div className="text-sm">{phaseText[phase] ?? ""}</div>}
+            {round > 0 && <div className="text-[10px] text-slate-500">Round {round} of {maxRounds}</div>}
+         </div>
+         {canStart
+            ? <button className="rounded border border-black px-3 py-1 text-sm" onClick={startGame}>Start game</button>
+            : <div className="w-12" />}
       </div>
    )
-}
\ No newline at end of file
+}
@@ -2,9 +2,10 @@ import type { Player, UiMessage } from "@/types/types"
 import { MobileToolBar } from "./ToolBar";
 import { PlayerTab } from "./PlayerTab";
 import { Chat, ChatInput } from "./Chat";
-import { Header } from "./Header";
+import { Header, type HeaderProps } from "./Header";
 
 export const MobileLayout = ({
+   header,
    canvasRef,
    handlePointerLeave,
    handlePointerDown,
@@ -24,6 +25,7 @@ export const MobileLayout = ({
    strokeColor,
    setStrokeColor,
 }: {
+   header: HeaderProps,
    canvasRef: React.RefObject<HTMLCanvasElement | null>,
    handlePointerLeave: (e: React.PointerEvent) => void,
    handlePointerDown: (e: React.PointerEvent) => void,
@@ -47,7 +49,7 @@ export const MobileLayout = ({
 
    return (
       <div className="flex flex-col h-screen min-h-screen z-100 gap-1 w-full">
-         <Header />
+         <Header {...header} />
          <div className="flex-shrink-0 overflow-hidden">
             <canvas
                ref={canvasRef}
@@ -1,10 +1,11 @@
 import type { Player, UiMessage } from "@/types/types"
 import { Chat, ChatInput } from "./Chat"
-import { Header } from "./Header"
+import { Header, type HeaderProps } from "./Header"
 import { PlayerTab } from "./PlayerTab"
 import { NormalToolbar } from "./ToolBar"
 
 interface NormalLayoutProps {
+   header: HeaderProps,
    canvasRef: React.RefObject<HTMLCanvasElement | null>,
    handlePointerLeave: (e: React.PointerEvent) => void,
    handlePointerDown: (e: React.PointerEvent) => void,
@@ -26,6 +27,7 @@ interface NormalLayoutProps {
 }
 
 export const NormalLayout = ({
+   header,
    canvasRef,
    handlePointerLeave,
    handlePointerDown,
@@ -47,7 +49,7 @@ export const NormalLayout = ({
 }: NormalLayoutProps) => {
    return (
       <div className="flex flex-col h-screen w-screen z-100 mt-20 p-2 gap-1">
-         <Header />
+         <Header {...header} />
          <div id="main" className="flex flex-1 gap-1">
             <PlayerTab
                className="w-52 shrink-0 h-fit overflow-y-auto"
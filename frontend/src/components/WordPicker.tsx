@@ -0,0 +1,27 @@
+// WordPicker offers the drawer the words to choose from at the start of a turn.
+export const WordPicker = ({
+   words,
+   chooseWord,
+}: {
+   words: string[],
+   chooseWord: (word: string) => void
+}) => {
+   return (
+      <div className="fixed inset-0 z-200 flex items-center justify-center bg-black/40">
+         <div className="flex flex-col items-center gap-3 rounded bg-white p-4 shadow">
+            <div className="text-sm font-semibold">Choose a word to draw</div>
+            <div className="flex flex-wrap justify-center gap-2">
+               {words.map(w => (
+                  <button
+                     key={w}
+                     className="rounded border border-black px-3 py-1"
+                     onClick={() => chooseWord(w)}
+                  >
+                     {w}
+                  </button>
+               ))}
+            </div>
+         </div>
+      </div>
+   )
+}
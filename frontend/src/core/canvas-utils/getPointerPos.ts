@@ -1,11 +1,12 @@
 import type React from "react";
 
+// Pointer position in the normalized canvas space the server expects:
+// (0,0) top left, (1,1) bottom right.
 export default function pointerPos(e: React.PointerEvent, canvasRef: React.RefObject<HTMLCanvasElement | null>) {
    const canvas = canvasRef.current!;
    const rect = canvas.getBoundingClientRect();
    return {
-      x: e.clientX - rect.left,
-      y: e.clientY - rect.top
+      x: (e.clientX - rect.left) / rect.width,
+      y: (e.clientY - rect.top) / rect.height
    };
 };
-
@@ -8,9 +8,9 @@ export default function drawIncomingPoint(ctxRef: React.RefObject<CanvasRenderin
    ctx.lineWidth = point.pointSize;
    if (point.type === "start") {
       ctx.beginPath()
-      ctx.moveTo(point.x, point.y)
+      ctx.moveTo(point.x * ctx.canvas.width, point.y * ctx.canvas.height)
    } else if (point.type === "move") {
-      ctx.lineTo(point.x, point.y)
+      ctx.lineTo(point.x * ctx.canvas.width, point.y * ctx.canvas.height)
       ctx.stroke()
    }
 }
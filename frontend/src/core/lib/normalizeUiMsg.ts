@@ -1,11 +1,9 @@
 import type { UiMessage, WSMessage } from "@/types/types";
 
 export default function normalizeInbound(msg: WSMessage): UiMessage | null {
-   // If server (legacy) wraps under type 'message' with inner msg.data.type, unwrap
-   const baseType = msg.type === 'message' && msg.data?.type ? msg.data.type : msg.type;
-   const payload = msg.type === 'message' && msg.data?.data ? msg.data.data : msg.data;
+   const payload = msg.data;
 
-   switch (baseType) {
+   switch (msg.type) {
       case 'chat_msg':
          return {
             type: 'chat_msg',
@@ -31,6 +29,24 @@ export default function normalizeInbound(msg: WSMessage): UiMessage | null {
             editDistance: payload.editDistance ?? 0,
             message: payload.message
          };
+      case 'turn_end':
+         return {
+            type: 'system',
+            message: payload.word ? `The word was ${payload.word}` : 'The turn was skipped'
+         };
+      case 'game_end': {
+         const ranking: { playerId: string, name: string }[] = payload.ranking ?? [];
+         const winners = ranking.filter(s => payload.winners?.includes(s.playerId)).map(s => s.name);
+         return {
+            type: 'system',
+            message: winners.length ? `Game over, ${winners.join(' and ')} won!` : 'Game over'
+         };
+      }
+      case 'error':
+         return {
+            type: 'system',
+            message: payload.message ?? 'Something went wrong'
+         };
       default:
          return null;
    }
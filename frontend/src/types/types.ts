@@ -1,6 +1,8 @@
 import { type Point as Pt } from "react-sketch-canvas";
 
 export type Stroke = {
+   id?: string;
+   seq?: number;
    strokeColor: string;
    strokeWidth: number;
    paths: Pt[];
@@ -13,14 +15,31 @@ export type WSMessage = {
    | 'stroke'
    | 'clear'
    | 'undo'
+   | 'redo'
+   | 'stroke_begin'
+   | 'stroke_points'
+   | 'stroke_end'
+   | 'stroke_cancel'
    | 'user_joined'
    | 'user_left'
    | 'game_state'
+   | 'phase_change'
    | 'guess'
    | 'chat_msg'
    | 'correct_guess'
    | 'close_guess'
-   | 'message'; // (legacy wrapper)
+   | 'error'
+   | 'welcome'
+   | 'player_renamed'
+   | 'start_game'
+   | 'choose_word'
+   | 'word_choices'
+   | 'word_chosen'
+   | 'hint'
+   | 'timer'
+   | 'turn_end'
+   | 'game_end'
+   | 'host_changed';
    data: any;
 };
 
@@ -46,7 +65,13 @@ export interface CloseGuessMsg {
    message?: string;
 }
 
-export type UiMessage = ChatMsg | CorrectGuessMsg | CloseGuessMsg;
+// game events and errors from the server, shown as a line in the chat
+export interface SystemMsg {
+   type: 'system';
+   message: string;
+}
+
+export type UiMessage = ChatMsg | CorrectGuessMsg | CloseGuessMsg | SystemMsg;
 // Remove old Message shape
 
 export type Point = {